@@ -1,28 +1,173 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
 	nethttp "net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
-	apphttp "dashboard-transporter/internal/http"
 	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/drift"
+	"dashboard-transporter/internal/gitrepo"
+	apphttp "dashboard-transporter/internal/http"
+	"dashboard-transporter/internal/lint"
+	"dashboard-transporter/internal/logging"
+	"dashboard-transporter/internal/notify"
+	"dashboard-transporter/internal/secrets"
+	"dashboard-transporter/internal/transport"
+	"dashboard-transporter/internal/webhooks"
 )
 
 func main() {
+	logging.Setup()
+
 	cfg := config.Load()
+	jobs := transport.NewJobs()
+
+	// lint é gate do import: configuração inválida não sobe
+	if cfg.LintRulesErr != nil {
+		slog.Error("invalid lint configuration", "error", cfg.LintRulesErr)
+		os.Exit(1)
+	}
+	linter, err := lint.New(cfg.LintRules)
+	if err != nil {
+		slog.Error("invalid lint configuration", "error", err)
+		os.Exit(1)
+	}
+	// idem p/ a varredura de segredos
+	if cfg.SecretScanErr != nil {
+		slog.Error("invalid secret scan configuration", "error", cfg.SecretScanErr)
+		os.Exit(1)
+	}
+	scanner, err := secrets.New(cfg.SecretScan)
+	if err != nil {
+		slog.Error("invalid secret scan configuration", "error", err)
+		os.Exit(1)
+	}
+	if cfg.GrantPoliciesErr != nil {
+		slog.Error("invalid permission policy", "error", cfg.GrantPoliciesErr)
+		os.Exit(1)
+	}
 
-	router := apphttp.NewRouter(cfg)
+	// repo Git é opcional: se falhar, sobe sem as rotas /git (respondem 501)
+	var repo *gitrepo.Repo
+	if cfg.Git.Enabled() {
+		r, err := gitrepo.Open(cfg.Git)
+		if err != nil {
+			slog.Error("git repository unavailable", "path", cfg.Git.Path, "error", err)
+		} else {
+			repo = r
+			slog.Info("git repository ready", "path", cfg.Git.Path, "branch", cfg.Git.Branch, "remote", cfg.Git.RemoteURL != "")
+		}
+	}
 
-	addr := ":8080"
-	if v := os.Getenv("PORT"); v != "" {
-		addr = ":" + v
+	sched := newDriftScheduler(cfg)
+	if sched != nil {
+		sched.Start()
 	}
 
-	log.Printf("Dashboard Transporter Backend listening on %s", addr)
+	hooks := webhooks.New(cfg.Webhooks)
+
+	router := apphttp.NewRouter(cfg, jobs, repo, sched, hooks, linter, scanner)
+	if err := apphttp.VerifyOpenAPI(router); err != nil {
+		slog.Error("openapi check failed", "error", err)
+		os.Exit(1)
+	}
+
+	srv := &nethttp.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           router, // ✅ O router já tem CORS via r.Use(...) dentro do NewRouter
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("Dashboard Transporter Backend listening", "addr", srv.Addr, "tls", cfg.Server.TLSEnabled())
+
+		var err error
+		if cfg.Server.TLSEnabled() {
+			err = srv.ListenAndServeTLS(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+		} else {
+			err = srv.ListenAndServe()
+		}
+		serveErr <- err
+	}()
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, nethttp.ErrServerClosed) {
+			slog.Error("server stopped", "error", err)
+			os.Exit(1)
+		}
+		return
+	case <-ctx.Done():
+	}
+
+	stop() // um segundo SIGTERM/CTRL+C mata na hora
+	slog.Info("shutdown requested; draining transport jobs",
+		"running_jobs", jobs.Running(),
+		"timeout", cfg.Server.ShutdownTimeout.String(),
+	)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	// 1) novos transportes => 503 (e /readyz => 503)
+	jobs.StopAccepting()
+
+	// 2) para de aceitar conexões e espera os handlers em andamento
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("http shutdown incomplete", "error", err)
+	}
+
+	// 3) cron de drift não dispara mais (espera a execução em andamento)
+	if sched != nil {
+		sched.Stop(shutdownCtx)
+	}
+
+	// 4) garante que nenhum job ficou pra trás
+	if err := jobs.Wait(shutdownCtx); err != nil {
+		slog.Error("shutdown deadline reached", "error", err)
+		os.Exit(1)
+	}
+
+	// 5) webhooks de fim de transporte ainda em retry
+	hooks.Close(shutdownCtx)
+
+	slog.Info("shutdown complete")
+}
+
+// newDriftScheduler monta o drift agendado. Sem DRIFT_SCHEDULE ainda dá p/ rodar
+// manualmente (POST /drift/runs) se baseline/target existirem; config inválida => nil.
+func newDriftScheduler(cfg *config.Config) *drift.Scheduler {
+	store, err := drift.NewStore(cfg.Drift.StoreDir, cfg.Drift.History)
+	if err != nil {
+		slog.Error("drift store unavailable; keeping runs in memory only", "dir", cfg.Drift.StoreDir, "error", err)
+		store, _ = drift.NewStore("", cfg.Drift.History)
+	}
+
+	notifiers := notify.FromConfig(cfg.Notify)
+	sched, err := drift.NewScheduler(cfg, store, notifiers)
+	if err != nil {
+		if cfg.Drift.Enabled() {
+			slog.Error("drift scheduler disabled", "error", err)
+		}
+		return nil
+	}
 
-	// ✅ O router já tem CORS via r.Use(...) dentro do NewRouter
-	if err := nethttp.ListenAndServe(addr, router); err != nil {
-		log.Fatal(err)
+	names := make([]string, 0, len(notifiers))
+	for _, n := range notifiers {
+		names = append(names, n.Name())
 	}
+	slog.Info("drift scheduler ready", "schedule", cfg.Drift.Schedule, "notify", names)
+	return sched
 }
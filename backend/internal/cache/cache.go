@@ -0,0 +1,126 @@
+// Package cache guarda em memória (com TTL) as leituras caras do Grafana
+// — árvore de folders e search de dashboards — por ambiente.
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxEntries limita as chaves guardadas: a chave do search inclui texto livre,
+// tags e folders, então cresce com o que os usuários digitam
+const maxEntries = 1000
+
+// Cache é um cache chave→valor com TTL e coalescing: chamadas concorrentes
+// para a mesma chave expirada fazem UM load só e todas recebem o resultado.
+type Cache[V any] struct {
+	ttl        time.Duration
+	now        func() time.Time
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]entry[V]
+	calls   map[string]*call[V]
+	gen     uint64 // incrementado a cada Invalidate
+}
+
+type entry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+	// gen da chave quando o load começou; se mudou (Invalidate), o resultado não é guardado
+	gen uint64
+}
+
+// New cria o cache; ttl <= 0 desliga o armazenamento (só coalescing)
+func New[V any](ttl time.Duration) *Cache[V] {
+	return &Cache[V]{
+		ttl:        ttl,
+		now:        time.Now,
+		maxEntries: maxEntries,
+		entries:    map[string]entry[V]{},
+		calls:      map[string]*call[V]{},
+	}
+}
+
+// Get devolve o valor da chave ou chama load (uma vez por chave, mesmo com concorrência).
+// O load roda sem o cancelamento do ctx de quem chegou primeiro: os outros dependem dele.
+func (c *Cache[V]) Get(ctx context.Context, key string, load func(ctx context.Context) (V, error)) (V, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		if c.now().Before(e.expires) {
+			c.mu.Unlock()
+			return e.value, nil
+		}
+		delete(c.entries, key)
+	}
+	if cl, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-cl.done:
+			return cl.value, cl.err
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		}
+	}
+
+	cl := &call[V]{done: make(chan struct{}), gen: c.gen}
+	c.calls[key] = cl
+	c.mu.Unlock()
+
+	cl.value, cl.err = load(context.WithoutCancel(ctx))
+
+	c.mu.Lock()
+	delete(c.calls, key)
+	if cl.err == nil && c.ttl > 0 && cl.gen == c.gen {
+		c.store(key, cl.value)
+	}
+	c.mu.Unlock()
+	close(cl.done)
+
+	return cl.value, cl.err
+}
+
+// Invalidate remove as chaves com o prefixo ("" = tudo)
+func (c *Cache[V]) Invalidate(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	for k := range c.entries {
+		if strings.HasPrefix(k, prefix) {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// store guarda a chave; cheio, descarta as vencidas e, se não bastar, a que vence primeiro.
+// Chamado com c.mu travado.
+func (c *Cache[V]) store(key string, value V) {
+	now := c.now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		for len(c.entries) >= c.maxEntries {
+			oldest, first := "", true
+			for k, e := range c.entries {
+				if first || e.expires.Before(c.entries[oldest].expires) {
+					oldest, first = k, false
+				}
+			}
+			delete(c.entries, oldest)
+		}
+	}
+	c.entries[key] = entry[V]{value: value, expires: now.Add(c.ttl)}
+}
@@ -0,0 +1,245 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// clock é um relógio manual p/ testar o TTL
+type clock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *clock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *clock) add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func newTestCache(ttl time.Duration) (*Cache[int], *clock) {
+	clk := &clock{t: time.Unix(0, 0)}
+	c := New[int](ttl)
+	c.now = clk.now
+	return c, clk
+}
+
+// counter devolve um load que conta as chamadas e responde o número da chamada
+func counter(n *atomic.Int32) func(context.Context) (int, error) {
+	return func(context.Context) (int, error) {
+		return int(n.Add(1)), nil
+	}
+}
+
+func TestGetTTL(t *testing.T) {
+	c, clk := newTestCache(time.Minute)
+	ctx := context.Background()
+	var n atomic.Int32
+
+	for i := 0; i < 3; i++ {
+		if v, _ := c.Get(ctx, "k", counter(&n)); v != 1 {
+			t.Fatalf("cached value = %d, want 1", v)
+		}
+	}
+
+	clk.add(time.Minute)
+	if v, _ := c.Get(ctx, "k", counter(&n)); v != 2 {
+		t.Errorf("after ttl = %d, want 2", v)
+	}
+}
+
+func TestGetExpiredEntryIsEvicted(t *testing.T) {
+	c, clk := newTestCache(time.Minute)
+	ctx := context.Background()
+	failing := func(context.Context) (int, error) { return 0, errors.New("down") }
+
+	_, _ = c.Get(ctx, "k", func(context.Context) (int, error) { return 1, nil })
+	clk.add(2 * time.Minute)
+
+	// o reload falhou: a entrada vencida sai e não volta
+	if _, err := c.Get(ctx, "k", failing); err == nil {
+		t.Fatal("want load error")
+	}
+	if len(c.entries) != 0 {
+		t.Errorf("entries = %v", c.entries)
+	}
+}
+
+func TestGetErrorIsNotCached(t *testing.T) {
+	c, _ := newTestCache(time.Minute)
+	ctx := context.Background()
+	calls := 0
+	load := func(context.Context) (int, error) {
+		calls++
+		return 0, errors.New("down")
+	}
+
+	_, _ = c.Get(ctx, "k", load)
+	_, _ = c.Get(ctx, "k", load)
+	if calls != 2 {
+		t.Errorf("load calls = %d, want 2", calls)
+	}
+}
+
+func TestGetZeroTTLOnlyCoalesces(t *testing.T) {
+	c, _ := newTestCache(0)
+	var n atomic.Int32
+
+	_, _ = c.Get(context.Background(), "k", counter(&n))
+	_, _ = c.Get(context.Background(), "k", counter(&n))
+	if n.Load() != 2 || len(c.entries) != 0 {
+		t.Errorf("loads = %d, entries = %d", n.Load(), len(c.entries))
+	}
+}
+
+func TestGetCoalesces(t *testing.T) {
+	c, _ := newTestCache(time.Minute)
+	release := make(chan struct{})
+	var n atomic.Int32
+	load := func(context.Context) (int, error) {
+		n.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make([]int, callers)
+	started := make(chan struct{}, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			started <- struct{}{}
+			results[i], _ = c.Get(context.Background(), "k", load)
+		}(i)
+	}
+	for i := 0; i < callers; i++ {
+		<-started
+	}
+	// dá tempo de todos entrarem no Get antes de liberar o load
+	for {
+		c.mu.Lock()
+		inflight := c.calls["k"] != nil
+		c.mu.Unlock()
+		if inflight {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n.Load() != 1 {
+		t.Errorf("load calls = %d, want 1", n.Load())
+	}
+	for i, v := range results {
+		if v != 42 {
+			t.Errorf("caller %d got %d", i, v)
+		}
+	}
+}
+
+func TestGetWaiterHonorsContext(t *testing.T) {
+	c, _ := newTestCache(time.Minute)
+	release := make(chan struct{})
+	defer close(release)
+	inflight := make(chan struct{})
+
+	go func() {
+		_, _ = c.Get(context.Background(), "k", func(context.Context) (int, error) {
+			close(inflight)
+			<-release
+			return 1, nil
+		})
+	}()
+	<-inflight
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Get(ctx, "k", func(context.Context) (int, error) { return 2, nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	c, _ := newTestCache(time.Minute)
+	ctx := context.Background()
+	var n atomic.Int32
+
+	_, _ = c.Get(ctx, "hml\x00a", counter(&n))
+	_, _ = c.Get(ctx, "prd\x00a", counter(&n))
+	c.Invalidate("hml\x00")
+
+	if _, ok := c.entries["hml\x00a"]; ok {
+		t.Error("hml key kept")
+	}
+	if _, ok := c.entries["prd\x00a"]; !ok {
+		t.Error("prd key dropped")
+	}
+}
+
+func TestInvalidateDuringLoadDropsResult(t *testing.T) {
+	c, _ := newTestCache(time.Minute)
+	ctx := context.Background()
+
+	// load em andamento quando o transporte invalida: o resultado (velho) não fica no cache
+	v, _ := c.Get(ctx, "k", func(context.Context) (int, error) {
+		c.Invalidate("")
+		return 1, nil
+	})
+	if v != 1 {
+		t.Errorf("value = %d", v)
+	}
+	if _, ok := c.entries["k"]; ok {
+		t.Error("stale load stored after Invalidate")
+	}
+
+	var n atomic.Int32
+	if v, _ := c.Get(ctx, "k", counter(&n)); v != 1 || n.Load() != 1 {
+		t.Errorf("next Get = %d, loads = %d", v, n.Load())
+	}
+}
+
+func TestMaxEntries(t *testing.T) {
+	c, clk := newTestCache(time.Minute)
+	c.maxEntries = 3
+	ctx := context.Background()
+	load := func(context.Context) (int, error) { return 1, nil }
+
+	for i := 0; i < 3; i++ {
+		_, _ = c.Get(ctx, fmt.Sprint(i), load)
+		clk.add(time.Second)
+	}
+	_, _ = c.Get(ctx, "3", load)
+	if len(c.entries) != 3 {
+		t.Fatalf("entries = %d, want 3", len(c.entries))
+	}
+	if _, ok := c.entries["0"]; ok {
+		t.Error("oldest entry kept")
+	}
+
+	// vencidas saem primeiro, sem derrubar as válidas
+	clk.add(time.Minute - 2*time.Second) // "1" venceu
+	_, _ = c.Get(ctx, "4", load)
+	for _, k := range []string{"2", "3", "4"} {
+		if _, ok := c.entries[k]; !ok {
+			t.Errorf("entry %s dropped", k)
+		}
+	}
+	if len(c.entries) != 3 {
+		t.Errorf("entries = %d, want 3", len(c.entries))
+	}
+}
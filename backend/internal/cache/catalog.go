@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/grafana"
+)
+
+// Catalog é o cache de folders e search de dashboards, com chave prefixada pelo ambiente
+type Catalog struct {
+	folders    *Cache[[]grafana.FolderOut]
+	dashboards *Cache[[]grafana.DashboardSearchItem]
+}
+
+// NewCatalog cria o catálogo; ttl <= 0 desliga o cache (continua coalescendo)
+func NewCatalog(ttl time.Duration) *Catalog {
+	return &Catalog{
+		folders:    New[[]grafana.FolderOut](ttl),
+		dashboards: New[[]grafana.DashboardSearchItem](ttl),
+	}
+}
+
+// Folders devolve o ListFoldersFlat do ambiente
+func (c *Catalog) Folders(ctx context.Context, env *config.Environment) ([]grafana.FolderOut, error) {
+	return c.folders.Get(ctx, envKey(env.ID), func(ctx context.Context) ([]grafana.FolderOut, error) {
+		return grafana.ClientForEnvironment(env).ListFoldersFlat(ctx)
+	})
+}
+
+// Dashboards devolve o SearchDashboards do ambiente p/ os filtros dados
+func (c *Catalog) Dashboards(ctx context.Context, env *config.Environment, q grafana.SearchQuery) ([]grafana.DashboardSearchItem, error) {
+	return c.dashboards.Get(ctx, envKey(env.ID)+searchKey(q), func(ctx context.Context) ([]grafana.DashboardSearchItem, error) {
+		return grafana.ClientForEnvironment(env).SearchDashboards(ctx, q)
+	})
+}
+
+// Invalidate descarta tudo do ambiente (ex. depois de um transporte p/ ele)
+func (c *Catalog) Invalidate(ctx context.Context, envID string) {
+	c.folders.Invalidate(envKey(envID))
+	c.dashboards.Invalidate(envKey(envID))
+	slog.DebugContext(ctx, "catalog cache invalidated", "env", envID)
+}
+
+func envKey(envID string) string {
+	return envID + "\x00"
+}
+
+func searchKey(q grafana.SearchQuery) string {
+	return strings.Join([]string{
+		q.Query,
+		strings.Join(q.Tags, ","),
+		strings.Join(q.FolderUIDs, ","),
+		strconv.FormatBool(q.Starred),
+	}, "\x00")
+}
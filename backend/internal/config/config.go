@@ -1,9 +1,13 @@
 package config
 
 import (
-	"log"
+	"encoding/json"
+	"fmt"
+	"log/slog"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Environment struct {
@@ -12,10 +16,188 @@ type Environment struct {
 	URL      string `json:"url"`
 	User     string `json:"-"`
 	Password string `json:"-"`
+	// Required indica se o ambiente fora do ar deixa o backend "not ready" (/readyz)
+	Required bool `json:"required"`
+}
+
+// ServerConfig controla o http.Server do backend
+type ServerConfig struct {
+	Addr              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ShutdownTimeout é quanto esperamos jobs de transporte em andamento no SIGTERM
+	ShutdownTimeout time.Duration
+	TLSCertFile     string
+	TLSKeyFile      string
+}
+
+// TLSEnabled indica se cert e key foram configurados
+func (s ServerConfig) TLSEnabled() bool {
+	return s.TLSCertFile != "" && s.TLSKeyFile != ""
+}
+
+// GitConfig liga o repositório Git local de dashboards (opcional)
+type GitConfig struct {
+	// Path é a working copy local; vazio = integração desligada
+	Path string
+	// RemoteURL é o "origin" (caminho local ou file://); vazio = só local
+	RemoteURL   string
+	Branch      string
+	AuthorName  string
+	AuthorEmail string
+}
+
+// Enabled indica se o repositório Git foi configurado
+func (g GitConfig) Enabled() bool {
+	return g.Path != ""
+}
+
+// DriftConfig controla o cálculo periódico de drift (opcional)
+type DriftConfig struct {
+	// Schedule é uma expressão cron de 5 campos (ou @hourly, @every 30m...); vazio = desligado
+	Schedule string
+	// Envs comparados (vazio = todos os configurados)
+	Envs []string
+	// Folders são paths ("Time A/Projeto X") incluindo subfolders; vazio = tudo
+	Folders []string
+	// BaselineEnv/TargetEnv: notifica quando TargetEnv diverge de BaselineEnv
+	BaselineEnv string
+	TargetEnv   string
+	// History é quantas execuções ficam guardadas
+	History int
+	// StoreDir persiste as execuções em JSON (vazio = só memória)
+	StoreDir string
+	// OrgID é a organização do Grafana lida pelo agendamento
+	OrgID string
+}
+
+// Enabled indica se o agendamento foi configurado
+func (d DriftConfig) Enabled() bool {
+	return d.Schedule != ""
+}
+
+// SMTPConfig é o servidor usado p/ notificações por email
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	From     string
+	To       []string
+}
+
+// Enabled indica se há servidor e destinatários
+func (s SMTPConfig) Enabled() bool {
+	return s.Host != "" && len(s.To) > 0
+}
+
+// NotifyConfig são os canais de notificação (todos opcionais)
+type NotifyConfig struct {
+	WebhookURL      string // POST do JSON completo
+	SlackWebhookURL string // payload {"text": ...} (Slack/Mattermost/Rocket.Chat)
+	SMTP            SMTPConfig
+}
+
+// WebhookEndpoint é um destino de webhooks de transporte
+type WebhookEndpoint struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Secret string `json:"secret"` // HMAC-SHA256 do body; vazio = sem assinatura
+	// Events filtra os eventos (ex. "transport.succeeded"); vazio = todos
+	Events []string `json:"events"`
+}
+
+// WebhooksConfig controla os webhooks de saída dos transportes
+type WebhooksConfig struct {
+	Endpoints   []WebhookEndpoint
+	MaxAttempts int
+	Timeout     time.Duration
+	// LogSize é quantas entregas ficam no log (memória)
+	LogSize int
+}
+
+// LintRule é uma regra de padrão organizacional p/ dashboards.
+// Type:
+// - tag_required: pelo menos uma tag casa Pattern (regex, ex. "^team:")
+// - description_required
+// - title_forbidden: título não pode casar Pattern (ex. "^Copy of")
+// - min_refresh: refresh automático não pode ser menor que Value (ex. "30s")
+// - max_panels: no máximo Max panels (rows não contam)
+type LintRule struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Severity string `json:"severity"` // error (default, bloqueia o import) | warning
+	// Envs são os ambientes de destino onde a regra vale; vazio = todos
+	Envs    []string `json:"envs"`
+	Pattern string   `json:"pattern,omitempty"`
+	Value   string   `json:"value,omitempty"`
+	Max     int      `json:"max,omitempty"`
+	Message string   `json:"message,omitempty"` // sobrescreve a mensagem padrão
+}
+
+// SecretPattern é um regex de segredo a procurar nos valores do dashboard
+type SecretPattern struct {
+	Name  string `json:"name"`
+	Regex string `json:"regex"`
+}
+
+// SecretScanConfig controla a varredura de segredos antes do import
+type SecretScanConfig struct {
+	Enabled bool
+	// Patterns extras (somados aos padrões embutidos: AWS, JWT, senha em URL...)
+	Patterns []SecretPattern
+	// Entropy mínima (Shannon, bits/char) p/ um token ser suspeito; 0 = sem check de entropia
+	Entropy   float64
+	MinLength int // tamanho mínimo do token p/ o check de entropia
+	// Allow são regex de valores que nunca são segredo (ex. placeholders)
+	Allow []string
+	// ProtectedEnvs bloqueiam o import com segredo; nos outros vira warning
+	ProtectedEnvs []string
+}
+
+// PermissionMapping traduz identidades da origem p/ o destino ao copiar permissões.
+// Chave = login/email (users) ou nome (teams) na origem; valor vazio = não copiar.
+type PermissionMapping struct {
+	Users map[string]string `json:"users"`
+	Teams map[string]string `json:"teams"`
+}
+
+// GrantPolicy limita as permissões concedidas num ambiente (grants e copyPermissions)
+type GrantPolicy struct {
+	MaxLevel string   `json:"maxLevel"` // view | edit | admin (vazio = admin)
+	Types    []string `json:"types"`    // user | team | role permitidos; vazio = todos
 }
 
 type Config struct {
 	Environments []Environment
+	Server       ServerConfig
+	Git          GitConfig
+	Drift        DriftConfig
+	Notify       NotifyConfig
+	Webhooks     WebhooksConfig
+	LintRules    []LintRule
+	// LintRulesErr = LINT_RULES/LINT_RULES_FILE ilegível ou inválido (o main aborta o startup:
+	// lint é gate, não pode desligar sozinho por typo)
+	LintRulesErr error
+	SecretScan   SecretScanConfig
+	// SecretScanErr = SECRET_SCAN_PATTERNS inválido (o main aborta o startup, como no lint)
+	SecretScanErr error
+	// ImportMessageTemplate é o text/template da mensagem de versão gravada no destino
+	// (IMPORT_MESSAGE_TEMPLATE; vazio = padrão)
+	ImportMessageTemplate string
+	// ProvenanceStamp liga por padrão o carimbo de procedência nos imports (PROVENANCE_STAMP)
+	ProvenanceStamp bool
+	// PermissionMapping é usado no copyPermissions do import
+	PermissionMapping PermissionMapping
+	// GrantPolicies por ambiente de destino (PERMISSION_POLICY)
+	GrantPolicies map[string]GrantPolicy
+	// GrantPoliciesErr = PERMISSION_POLICY inválido (o main aborta o startup: a política
+	// é o limite das permissões concedidas, não pode voltar ao padrão por typo)
+	GrantPoliciesErr error
+	// CacheTTL é a validade do cache de folders/dashboards por ambiente (0 = sem cache)
+	CacheTTL time.Duration
 }
 
 func Load() *Config {
@@ -37,16 +219,348 @@ func Load() *Config {
 	}
 
 	if len(envs) == 0 {
-		log.Printf("[CONFIG] WARNING: Nenhum ambiente configurado via env vars (GRAFANA_*_URL)")
+		slog.Warn("nenhum ambiente configurado via env vars (GRAFANA_*_URL)")
 	}
 
 	for _, e := range envs {
-		log.Printf("[CONFIG] %s - URL: %s, User: %s", strings.ToUpper(e.ID), e.URL, e.User)
+		slog.Info("environment configured", "env", e.ID, "url", e.URL, "user", e.User, "required", e.Required)
 	}
 
+	lintRules, lintErr := loadLintRules()
+	secretScan, secretScanErr := loadSecretScan()
+	grantPolicies, grantPoliciesErr := loadGrantPolicies()
+
 	return &Config{
-		Environments: envs,
+		Environments:          envs,
+		Server:                loadServer(),
+		Git:                   loadGit(),
+		Drift:                 loadDrift(),
+		Notify:                loadNotify(),
+		Webhooks:              loadWebhooks(),
+		LintRules:             lintRules,
+		LintRulesErr:          lintErr,
+		SecretScan:            secretScan,
+		SecretScanErr:         secretScanErr,
+		ImportMessageTemplate: os.Getenv("IMPORT_MESSAGE_TEMPLATE"),
+		ProvenanceStamp:       envBool("PROVENANCE_STAMP", false),
+		PermissionMapping:     loadPermissionMapping(),
+		GrantPolicies:         grantPolicies,
+		GrantPoliciesErr:      grantPoliciesErr,
+		CacheTTL:              envDuration("CACHE_TTL", time.Minute),
+	}
+}
+
+// loadGrantPolicies lê PERMISSION_POLICY: JSON {"prd":{"maxLevel":"edit","types":["team"]}}.
+// Sem a variável, vale o padrão "sem Admin em PRD". JSON, maxLevel ou type inválido é erro.
+func loadGrantPolicies() (map[string]GrantPolicy, error) {
+	policies := map[string]GrantPolicy{"prd": {MaxLevel: "edit"}}
+	if raw := strings.TrimSpace(os.Getenv("PERMISSION_POLICY")); raw != "" {
+		var in map[string]GrantPolicy
+		if err := json.Unmarshal([]byte(raw), &in); err != nil {
+			return nil, fmt.Errorf("PERMISSION_POLICY: invalid json: %w", err)
+		}
+		policies = map[string]GrantPolicy{}
+		for env, p := range in {
+			policies[strings.ToLower(strings.TrimSpace(env))] = p
+		}
+	}
+
+	for env, p := range policies {
+		p.MaxLevel = strings.ToLower(strings.TrimSpace(p.MaxLevel))
+		switch p.MaxLevel {
+		case "":
+			p.MaxLevel = "admin"
+		case "view", "edit", "admin":
+		default:
+			return nil, fmt.Errorf("PERMISSION_POLICY: invalid maxLevel %q for env %s (view, edit or admin)", p.MaxLevel, env)
+		}
+		for i, t := range p.Types {
+			p.Types[i] = strings.ToLower(strings.TrimSpace(t))
+			switch p.Types[i] {
+			case "user", "team", "role":
+			default:
+				return nil, fmt.Errorf("PERMISSION_POLICY: invalid type %q for env %s (user, team or role)", t, env)
+			}
+		}
+		policies[env] = p
+		slog.Info("grant policy configured", "env", env, "max_level", p.MaxLevel, "types", p.Types)
+	}
+	return policies, nil
+}
+
+// loadPermissionMapping lê PERMISSION_MAPPING (JSON {"users":{...},"teams":{...}})
+// ou PERMISSION_MAPPING_FILE (caminho do mesmo JSON). Chaves sem diferença de caixa.
+func loadPermissionMapping() PermissionMapping {
+	var m PermissionMapping
+	raw := strings.TrimSpace(os.Getenv("PERMISSION_MAPPING"))
+	if raw == "" {
+		if path := strings.TrimSpace(os.Getenv("PERMISSION_MAPPING_FILE")); path != "" {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				slog.Warn("PERMISSION_MAPPING_FILE ilegível, ignorando", "path", path, "error", err)
+				return m
+			}
+			raw = string(b)
+		}
+	}
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), &m); err != nil {
+			slog.Warn("PERMISSION_MAPPING inválido, ignorando", "error", err)
+			return PermissionMapping{}
+		}
+	}
+
+	m.Users = lowerKeys(m.Users)
+	m.Teams = lowerKeys(m.Teams)
+	return m
+}
+
+func lowerKeys(in map[string]string) map[string]string {
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		out[strings.ToLower(strings.TrimSpace(k))] = strings.TrimSpace(v)
+	}
+	return out
+}
+
+// loadSecretScan lê:
+// - SECRET_SCAN_ENABLED (default true)
+// - SECRET_SCAN_PATTERNS: JSON [{"name","regex"}] (somados aos embutidos)
+// - SECRET_SCAN_ENTROPY (default 4.0; 0 desliga) / SECRET_SCAN_MIN_LENGTH (default 20)
+// - SECRET_SCAN_ALLOW: regex separados por vírgula
+// - SECRET_SCAN_PROTECTED_ENVS (default "prd")
+func loadSecretScan() (SecretScanConfig, error) {
+	s := SecretScanConfig{
+		Enabled:       envBool("SECRET_SCAN_ENABLED", true),
+		Entropy:       4.0,
+		MinLength:     envInt("SECRET_SCAN_MIN_LENGTH", 20),
+		Allow:         envList("SECRET_SCAN_ALLOW"),
+		ProtectedEnvs: envList("SECRET_SCAN_PROTECTED_ENVS"),
+	}
+	if v := strings.TrimSpace(os.Getenv("SECRET_SCAN_ENTROPY")); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			slog.Warn("SECRET_SCAN_ENTROPY inválido, usando default", "value", v, "default", s.Entropy)
+		} else {
+			s.Entropy = f
+		}
+	}
+	if len(s.ProtectedEnvs) == 0 {
+		s.ProtectedEnvs = []string{"prd"}
+	}
+	for i, env := range s.ProtectedEnvs {
+		s.ProtectedEnvs[i] = strings.ToLower(env)
+	}
+	if raw := strings.TrimSpace(os.Getenv("SECRET_SCAN_PATTERNS")); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &s.Patterns); err != nil {
+			return s, fmt.Errorf("SECRET_SCAN_PATTERNS: invalid json: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// loadLintRules lê as regras de lint (JSON [{"id","type","severity","envs",...}]) de:
+// - LINT_RULES (inline)
+// - LINT_RULES_FILE (caminho de um arquivo; usado se LINT_RULES estiver vazio)
+func loadLintRules() ([]LintRule, error) {
+	raw := strings.TrimSpace(os.Getenv("LINT_RULES"))
+	if raw == "" {
+		path := strings.TrimSpace(os.Getenv("LINT_RULES_FILE"))
+		if path == "" {
+			return nil, nil
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("LINT_RULES_FILE: %w", err)
+		}
+		raw = string(b)
+	}
+
+	var rules []LintRule
+	if err := json.Unmarshal([]byte(raw), &rules); err != nil {
+		return nil, fmt.Errorf("LINT_RULES: invalid json: %w", err)
+	}
+	for i := range rules {
+		if rules[i].ID == "" {
+			rules[i].ID = fmt.Sprintf("%s-%d", rules[i].Type, i+1)
+		}
+		for j, env := range rules[i].Envs {
+			rules[i].Envs[j] = strings.ToLower(strings.TrimSpace(env))
+		}
+	}
+	return rules, nil
+}
+
+// loadWebhooks lê:
+// - WEBHOOKS: JSON [{"name","url","secret","events":[...]}]
+// - WEBHOOK_URL / WEBHOOK_SECRET / WEBHOOK_EVENTS (atalho p/ um destino só)
+// - WEBHOOK_MAX_ATTEMPTS (default 5), WEBHOOK_TIMEOUT (default 10s), WEBHOOK_LOG_SIZE (default 200)
+func loadWebhooks() WebhooksConfig {
+	w := WebhooksConfig{
+		MaxAttempts: envInt("WEBHOOK_MAX_ATTEMPTS", 5),
+		Timeout:     envDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		LogSize:     envInt("WEBHOOK_LOG_SIZE", 200),
+	}
+	if w.MaxAttempts <= 0 {
+		w.MaxAttempts = 1
+	}
+	if w.LogSize <= 0 {
+		w.LogSize = 200
+	}
+
+	if raw := strings.TrimSpace(os.Getenv("WEBHOOKS")); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &w.Endpoints); err != nil {
+			slog.Warn("WEBHOOKS inválido, ignorando", "error", err)
+			w.Endpoints = nil
+		}
+	}
+	if u := strings.TrimSpace(os.Getenv("WEBHOOK_URL")); u != "" {
+		w.Endpoints = append(w.Endpoints, WebhookEndpoint{
+			Name:   "default",
+			URL:    u,
+			Secret: os.Getenv("WEBHOOK_SECRET"),
+			Events: envList("WEBHOOK_EVENTS"),
+		})
+	}
+
+	valid := w.Endpoints[:0]
+	for i, e := range w.Endpoints {
+		if strings.TrimSpace(e.URL) == "" {
+			slog.Warn("webhook sem url, ignorando", "index", i, "name", e.Name)
+			continue
+		}
+		if e.Name == "" {
+			e.Name = fmt.Sprintf("webhook-%d", i+1)
+		}
+		valid = append(valid, e)
+		slog.Info("webhook configured", "name", e.Name, "events", e.Events, "signed", e.Secret != "")
+	}
+	w.Endpoints = valid
+	return w
+}
+
+// loadDrift lê:
+// - DRIFT_SCHEDULE (cron; liga o agendamento)
+// - DRIFT_ENVS (ex. "hml,prd"; vazio = todos)
+// - DRIFT_FOLDERS (paths separados por vírgula; vazio = tudo)
+// - DRIFT_BASELINE_ENV / DRIFT_TARGET_ENV (default hml / prd)
+// - DRIFT_HISTORY (default 50)
+// - DRIFT_STORE_DIR (opcional)
+// - DRIFT_ORG_ID (default 1)
+func loadDrift() DriftConfig {
+	d := DriftConfig{
+		Schedule:    strings.TrimSpace(os.Getenv("DRIFT_SCHEDULE")),
+		Envs:        envList("DRIFT_ENVS"),
+		Folders:     envList("DRIFT_FOLDERS"),
+		BaselineEnv: strings.ToLower(strings.TrimSpace(os.Getenv("DRIFT_BASELINE_ENV"))),
+		TargetEnv:   strings.ToLower(strings.TrimSpace(os.Getenv("DRIFT_TARGET_ENV"))),
+		History:     envInt("DRIFT_HISTORY", 50),
+		StoreDir:    strings.TrimSpace(os.Getenv("DRIFT_STORE_DIR")),
+		OrgID:       strings.TrimSpace(os.Getenv("DRIFT_ORG_ID")),
+	}
+	if d.OrgID == "" {
+		d.OrgID = "1"
+	}
+	if d.BaselineEnv == "" {
+		d.BaselineEnv = "hml"
+	}
+	if d.TargetEnv == "" {
+		d.TargetEnv = "prd"
+	}
+	if d.History <= 0 {
+		d.History = 50
+	}
+	if d.Enabled() {
+		slog.Info("drift schedule configured", "schedule", d.Schedule, "baseline", d.BaselineEnv, "target", d.TargetEnv, "folders", d.Folders)
+	}
+	return d
+}
+
+// loadNotify lê:
+// - NOTIFY_WEBHOOK_URL (JSON genérico)
+// - NOTIFY_SLACK_WEBHOOK_URL (payload compatível com Slack)
+// - SMTP_HOST, SMTP_PORT (default 587), SMTP_USER, SMTP_PASSWORD, SMTP_FROM, NOTIFY_EMAIL_TO
+func loadNotify() NotifyConfig {
+	n := NotifyConfig{
+		WebhookURL:      strings.TrimSpace(os.Getenv("NOTIFY_WEBHOOK_URL")),
+		SlackWebhookURL: strings.TrimSpace(os.Getenv("NOTIFY_SLACK_WEBHOOK_URL")),
+		SMTP: SMTPConfig{
+			Host:     strings.TrimSpace(os.Getenv("SMTP_HOST")),
+			Port:     strings.TrimSpace(os.Getenv("SMTP_PORT")),
+			User:     strings.TrimSpace(os.Getenv("SMTP_USER")),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     strings.TrimSpace(os.Getenv("SMTP_FROM")),
+			To:       envList("NOTIFY_EMAIL_TO"),
+		},
 	}
+	if n.SMTP.Port == "" {
+		n.SMTP.Port = "587"
+	}
+	if n.SMTP.From == "" {
+		n.SMTP.From = "dashboard-transporter@localhost"
+	}
+	return n
+}
+
+// loadGit lê:
+// - GIT_REPO_PATH (liga a integração)
+// - GIT_REMOTE_URL (opcional; caminho local ou file://)
+// - GIT_BRANCH (default main)
+// - GIT_AUTHOR_NAME / GIT_AUTHOR_EMAIL (autor dos commits)
+func loadGit() GitConfig {
+	g := GitConfig{
+		Path:        strings.TrimSpace(os.Getenv("GIT_REPO_PATH")),
+		RemoteURL:   strings.TrimSpace(os.Getenv("GIT_REMOTE_URL")),
+		Branch:      strings.TrimSpace(os.Getenv("GIT_BRANCH")),
+		AuthorName:  strings.TrimSpace(os.Getenv("GIT_AUTHOR_NAME")),
+		AuthorEmail: strings.TrimSpace(os.Getenv("GIT_AUTHOR_EMAIL")),
+	}
+	if g.Branch == "" {
+		g.Branch = "main"
+	}
+	if g.AuthorName == "" {
+		g.AuthorName = "Dashboard Transporter"
+	}
+	if g.AuthorEmail == "" {
+		g.AuthorEmail = "dashboard-transporter@localhost"
+	}
+	if g.Enabled() {
+		slog.Info("git repository configured", "path", g.Path, "remote", g.RemoteURL, "branch", g.Branch)
+	}
+	return g
+}
+
+// loadServer lê:
+// - PORT (default 8080)
+// - HTTP_READ_TIMEOUT, HTTP_READ_HEADER_TIMEOUT, HTTP_WRITE_TIMEOUT, HTTP_IDLE_TIMEOUT
+// - SHUTDOWN_TIMEOUT
+// - TLS_CERT_FILE / TLS_KEY_FILE (os dois => HTTPS)
+//
+// Durations no formato do Go: "30s", "5m"...
+func loadServer() ServerConfig {
+	addr := ":8080"
+	if v := strings.TrimSpace(os.Getenv("PORT")); v != "" {
+		addr = ":" + v
+	}
+
+	s := ServerConfig{
+		Addr:              addr,
+		ReadTimeout:       envDuration("HTTP_READ_TIMEOUT", 30*time.Second),
+		ReadHeaderTimeout: envDuration("HTTP_READ_HEADER_TIMEOUT", 10*time.Second),
+		// batch de import em PRD pode demorar (GET + POST + RBAC por dashboard)
+		WriteTimeout:    envDuration("HTTP_WRITE_TIMEOUT", 5*time.Minute),
+		IdleTimeout:     envDuration("HTTP_IDLE_TIMEOUT", 2*time.Minute),
+		ShutdownTimeout: envDuration("SHUTDOWN_TIMEOUT", 60*time.Second),
+		TLSCertFile:     strings.TrimSpace(os.Getenv("TLS_CERT_FILE")),
+		TLSKeyFile:      strings.TrimSpace(os.Getenv("TLS_KEY_FILE")),
+	}
+
+	if (s.TLSCertFile == "") != (s.TLSKeyFile == "") {
+		slog.Warn("TLS_CERT_FILE e TLS_KEY_FILE precisam vir juntos; subindo sem TLS")
+		s.TLSCertFile, s.TLSKeyFile = "", ""
+	}
+
+	return s
 }
 
 // buildEnv lê:
@@ -54,6 +568,7 @@ func Load() *Config {
 // - GRAFANA_<SUFFIX>_USER
 // - GRAFANA_<SUFFIX>_PASS (preferencial)
 // - GRAFANA_<SUFFIX>_PASSWORD (fallback p/ compatibilidade)
+// - GRAFANA_<SUFFIX>_REQUIRED (opcional, default true)
 func buildEnv(suffix string, displayName string) *Environment {
 	url := strings.TrimSpace(os.Getenv("GRAFANA_" + suffix + "_URL"))
 	if url == "" {
@@ -74,7 +589,59 @@ func buildEnv(suffix string, displayName string) *Environment {
 		URL:      url,
 		User:     user,
 		Password: pass,
+		Required: envBool("GRAFANA_"+suffix+"_REQUIRED", true),
+	}
+}
+
+// envBool lê um booleano ("true/false/1/0/yes/no"); vazio ou inválido => def
+func envBool(key string, def bool) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(key))) {
+	case "1", "true", "yes", "y", "on":
+		return true
+	case "0", "false", "no", "n", "off":
+		return false
+	default:
+		return def
+	}
+}
+
+// envList lê uma lista separada por vírgula (itens vazios descartados)
+func envList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// envInt lê um inteiro; vazio ou inválido => def
+func envInt(key string, def int) int {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		slog.Warn("inteiro inválido, usando default", "key", key, "value", v, "default", def)
+		return def
+	}
+	return n
+}
+
+// envDuration lê uma duration ("30s", "2m"); vazio ou inválido => def
+func envDuration(key string, def time.Duration) time.Duration {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		slog.Warn("duration inválida, usando default", "key", key, "value", v, "default", def.String())
+		return def
 	}
+	return d
 }
 
 /*
@@ -0,0 +1,304 @@
+package drift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/grafana"
+	"dashboard-transporter/internal/transport"
+)
+
+// quantos dashboards buscamos em paralelo por ambiente
+const fetchWorkers = 4
+
+// status de um dashboard no relatório
+const (
+	StatusInSync  = "in_sync" // presente em todos os ambientes, mesmo conteúdo
+	StatusDrifted = "drifted" // conteúdo diferente entre ambientes
+	StatusMissing = "missing" // mesmo conteúdo, mas ausente em algum ambiente (nunca promovido)
+)
+
+// Copy é o estado de um dashboard em um ambiente
+type Copy struct {
+	Present    bool   `json:"present"`
+	Title      string `json:"title,omitempty"`
+	Version    int    `json:"version,omitempty"`
+	FolderUID  string `json:"folderUid,omitempty"`
+	FolderPath string `json:"folderPath,omitempty"`
+	Hash       string `json:"hash,omitempty"`
+	// Error = o dashboard está na busca mas não deu p/ ler (não conta como ausente)
+	Error string `json:"error,omitempty"`
+}
+
+// Entry é uma linha do relatório (um UID em todos os ambientes)
+type Entry struct {
+	UID          string          `json:"uid"`
+	Title        string          `json:"title"`
+	Status       string          `json:"status"`
+	HashesMatch  bool            `json:"hashesMatch"`
+	MissingIn    []string        `json:"missingIn"`
+	Environments map[string]Copy `json:"environments"`
+}
+
+// Error é uma falha ao ler um ambiente (UID vazio) ou um dashboard
+type Error struct {
+	Env     string `json:"env"`
+	UID     string `json:"uid,omitempty"`
+	Message string `json:"message"`
+}
+
+// Summary agrega os status do relatório
+type Summary struct {
+	Total   int `json:"total"`
+	InSync  int `json:"inSync"`
+	Drifted int `json:"drifted"`
+	Missing int `json:"missing"`
+}
+
+// Report é o resultado de uma comparação entre ambientes
+type Report struct {
+	GeneratedAt  time.Time `json:"generatedAt"`
+	Environments []string  `json:"environments"`
+	Summary      Summary   `json:"summary"`
+	Dashboards   []Entry   `json:"dashboards"`
+	Errors       []Error   `json:"errors"`
+}
+
+// snapshot é o que foi lido de um ambiente
+type snapshot struct {
+	env    string
+	ok     bool // false = ambiente inteiro indisponível (não conta como "missing")
+	copies map[string]Copy
+	errors []Error
+}
+
+// ResolveEnvironments resolve os ids (vazio = todos os configurados, na ordem da config)
+func ResolveEnvironments(cfg *config.Config, ids []string) ([]*config.Environment, error) {
+	var out []*config.Environment
+	seen := map[string]bool{}
+
+	if len(ids) == 0 {
+		for i := range cfg.Environments {
+			out = append(out, &cfg.Environments[i])
+		}
+	} else {
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			env := cfg.GetEnvironment(id)
+			if env == nil {
+				return nil, fmt.Errorf("unknown env: %s", id)
+			}
+			out = append(out, env)
+		}
+	}
+
+	if len(out) < 2 {
+		return nil, errors.New("at least two envs are required")
+	}
+	return out, nil
+}
+
+// Compute indexa os dashboards por UID em cada ambiente e compara o conteúdo normalizado.
+// orgID é a organização lida em todos os ambientes; folders (paths, com subfolders)
+// restringe o relatório; vazio = tudo.
+func Compute(ctx context.Context, envs []*config.Environment, orgID string, folders []string) *Report {
+	snaps := make([]snapshot, len(envs))
+
+	var wg sync.WaitGroup
+	for i, env := range envs {
+		wg.Add(1)
+		go func(i int, env *config.Environment) {
+			defer wg.Done()
+			snaps[i] = collect(ctx, env, orgID)
+		}(i, env)
+	}
+	wg.Wait()
+
+	return build(snaps, folders)
+}
+
+func collect(ctx context.Context, env *config.Environment, orgID string) snapshot {
+	snap := snapshot{env: env.ID, copies: map[string]Copy{}}
+	client := grafana.ClientForEnvironment(env).WithOrg(orgID)
+
+	folders, err := client.ListFoldersFlat(ctx)
+	if err != nil {
+		snap.errors = append(snap.errors, Error{Env: env.ID, Message: "list folders: " + err.Error()})
+		return snap
+	}
+	folderPath := make(map[string]string, len(folders))
+	for _, f := range folders {
+		folderPath[f.UID] = f.Title
+	}
+
+	items, err := client.ListDashboards(ctx)
+	if err != nil {
+		snap.errors = append(snap.errors, Error{Env: env.ID, Message: "list dashboards: " + err.Error()})
+		return snap
+	}
+	snap.ok = true
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		work = make(chan grafana.DashboardSearchItem)
+	)
+	for w := 0; w < fetchWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for it := range work {
+				c, err := fetchCopy(ctx, client, it, folderPath)
+				mu.Lock()
+				if err != nil {
+					snap.errors = append(snap.errors, Error{Env: env.ID, UID: it.UID, Message: err.Error()})
+					snap.copies[it.UID] = Copy{Error: err.Error()}
+				} else {
+					snap.copies[it.UID] = c
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, it := range items {
+		work <- it
+	}
+	close(work)
+	wg.Wait()
+
+	return snap
+}
+
+func fetchCopy(ctx context.Context, client *grafana.Client, it grafana.DashboardSearchItem, folderPath map[string]string) (Copy, error) {
+	full, err := client.GetDashboardFull(ctx, it.UID)
+	if err != nil {
+		return Copy{}, err
+	}
+	hash, err := transport.ContentHash(full.Dashboard)
+	if err != nil {
+		return Copy{}, fmt.Errorf("hash: %w", err)
+	}
+
+	title, _ := full.Dashboard["title"].(string)
+	version, _ := full.Dashboard["version"].(float64)
+	folderUID := full.FolderUID()
+	path, found := folderPath[folderUID]
+	if !found {
+		path = full.FolderTitle()
+	}
+
+	return Copy{
+		Present:    true,
+		Title:      title,
+		Version:    int(version),
+		FolderUID:  folderUID,
+		FolderPath: path,
+		Hash:       hash,
+	}, nil
+}
+
+func build(snaps []snapshot, folders []string) *Report {
+	rep := &Report{
+		GeneratedAt:  time.Now().UTC(),
+		Environments: make([]string, 0, len(snaps)),
+		Dashboards:   []Entry{},
+		Errors:       []Error{},
+	}
+
+	uids := map[string]bool{}
+	for _, s := range snaps {
+		rep.Environments = append(rep.Environments, s.env)
+		rep.Errors = append(rep.Errors, s.errors...)
+		for uid := range s.copies {
+			uids[uid] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(uids))
+	for uid := range uids {
+		sorted = append(sorted, uid)
+	}
+	sort.Strings(sorted)
+
+	for _, uid := range sorted {
+		e := Entry{
+			UID:          uid,
+			HashesMatch:  true,
+			MissingIn:    []string{},
+			Environments: make(map[string]Copy, len(snaps)),
+		}
+
+		hash := ""
+		for _, s := range snaps {
+			c, found := s.copies[uid]
+			if !found {
+				// ambiente fora do ar não prova que o dashboard não existe lá
+				if s.ok {
+					e.MissingIn = append(e.MissingIn, s.env)
+				}
+				e.Environments[s.env] = Copy{}
+				continue
+			}
+			e.Environments[s.env] = c
+			if c.Error != "" {
+				continue // leitura falhou: nem ausente nem comparável
+			}
+			if e.Title == "" {
+				e.Title = c.Title
+			}
+			if hash == "" {
+				hash = c.Hash
+			} else if c.Hash != hash {
+				e.HashesMatch = false
+			}
+		}
+
+		if !inFolders(e, folders) {
+			continue
+		}
+
+		switch {
+		case !e.HashesMatch:
+			e.Status = StatusDrifted
+			rep.Summary.Drifted++
+		case len(e.MissingIn) > 0:
+			e.Status = StatusMissing
+			rep.Summary.Missing++
+		default:
+			e.Status = StatusInSync
+			rep.Summary.InSync++
+		}
+		rep.Dashboards = append(rep.Dashboards, e)
+	}
+	rep.Summary.Total = len(rep.Dashboards)
+
+	return rep
+}
+
+// inFolders: o dashboard entra se, em algum ambiente, está num dos folders (ou subfolder)
+func inFolders(e Entry, folders []string) bool {
+	if len(folders) == 0 {
+		return true
+	}
+	for _, c := range e.Environments {
+		if !c.Present {
+			continue
+		}
+		for _, f := range folders {
+			if c.FolderPath == f || strings.HasPrefix(c.FolderPath, f+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
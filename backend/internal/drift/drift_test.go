@@ -0,0 +1,91 @@
+package drift
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildAndDivergences(t *testing.T) {
+	same := Copy{Present: true, Title: "Same", Version: 1, FolderPath: "Ops", Hash: "h1"}
+	snaps := []snapshot{
+		{env: "hml", ok: true, copies: map[string]Copy{
+			"same":    same,
+			"changed": {Present: true, Title: "Changed", Version: 2, Hash: "h2"},
+			"new":     {Present: true, Title: "New", Version: 1, Hash: "h3"},
+			"flaky":   {Present: true, Title: "Flaky", Version: 1, Hash: "h4"},
+		}},
+		{env: "prd", ok: true, copies: map[string]Copy{
+			"same":    same,
+			"changed": {Present: true, Title: "Changed", Version: 1, Hash: "hx"},
+			"manual":  {Present: true, Title: "Manual", Version: 1, Hash: "h5"},
+			"flaky":   {Error: "dashboard flaky: 500"}, // está na busca, GET falhou
+		}, errors: []Error{{Env: "prd", UID: "flaky", Message: "dashboard flaky: 500"}}},
+		{env: "dev", ok: false, copies: map[string]Copy{}, errors: []Error{{Env: "dev", Message: "list dashboards: timeout"}}},
+	}
+
+	rep := build(snaps, nil)
+
+	byUID := map[string]Entry{}
+	for _, e := range rep.Dashboards {
+		byUID[e.UID] = e
+	}
+
+	cases := []struct {
+		uid       string
+		status    string
+		missingIn []string
+	}{
+		{"same", StatusInSync, []string{}},
+		{"changed", StatusDrifted, []string{}},
+		{"new", StatusMissing, []string{"prd"}}, // dev fora do ar não conta
+		{"manual", StatusMissing, []string{"hml"}},
+		{"flaky", StatusInSync, []string{}}, // falha de leitura não é ausência
+	}
+	for _, c := range cases {
+		e, ok := byUID[c.uid]
+		if !ok {
+			t.Fatalf("%s: not in report", c.uid)
+		}
+		if e.Status != c.status || !reflect.DeepEqual(e.MissingIn, c.missingIn) {
+			t.Errorf("%s: status = %q missingIn = %v, want %q %v", c.uid, e.Status, e.MissingIn, c.status, c.missingIn)
+		}
+	}
+	if got := byUID["flaky"].Environments["prd"].Error; got == "" {
+		t.Error("flaky: read error not kept in the prd copy")
+	}
+	if rep.Summary != (Summary{Total: 5, InSync: 2, Drifted: 1, Missing: 2}) {
+		t.Errorf("summary = %+v", rep.Summary)
+	}
+	if len(rep.Errors) != 2 {
+		t.Errorf("errors = %+v", rep.Errors)
+	}
+
+	got := map[string]string{}
+	for _, d := range divergences(rep, "hml", "prd") {
+		got[d.UID] = d.Kind
+	}
+	want := map[string]string{
+		"changed": KindChanged,
+		"new":     KindMissingInTarget,
+		"manual":  KindOnlyInTarget,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("divergences = %v, want %v", got, want)
+	}
+}
+
+func TestBuildFolders(t *testing.T) {
+	snaps := []snapshot{
+		{env: "hml", ok: true, copies: map[string]Copy{
+			"a": {Present: true, FolderPath: "Time A/Projeto X", Hash: "1"},
+			"b": {Present: true, FolderPath: "Time B", Hash: "2"},
+			"c": {Present: true, FolderPath: "Time AB", Hash: "3"},
+		}},
+		{env: "prd", ok: true, copies: map[string]Copy{}},
+	}
+
+	rep := build(snaps, []string{"Time A"})
+	if len(rep.Dashboards) != 1 || rep.Dashboards[0].UID != "a" {
+		t.Errorf("dashboards = %+v", rep.Dashboards)
+	}
+}
@@ -0,0 +1,323 @@
+package drift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/logging"
+	"dashboard-transporter/internal/notify"
+
+	"github.com/robfig/cron/v3"
+)
+
+// tempo máximo de uma execução (lê todos os dashboards de todos os ambientes)
+const runTimeout = 10 * time.Minute
+
+// ErrRunning: já existe uma execução em andamento
+var ErrRunning = errors.New("drift run already in progress")
+
+// tipos de divergência entre baseline (HML) e target (PRD)
+const (
+	KindChanged         = "changed"           // conteúdo diferente
+	KindMissingInTarget = "missing_in_target" // nunca promovido
+	KindOnlyInTarget    = "only_in_target"    // criado direto no target
+)
+
+// Divergence é um dashboard em que o target diverge do baseline
+type Divergence struct {
+	UID             string `json:"uid"`
+	Title           string `json:"title"`
+	Kind            string `json:"kind"`
+	BaselineVersion int    `json:"baselineVersion,omitempty"`
+	TargetVersion   int    `json:"targetVersion,omitempty"`
+	BaselineFolder  string `json:"baselineFolder,omitempty"`
+	TargetFolder    string `json:"targetFolder,omitempty"`
+}
+
+// Run é uma execução (agendada ou manual) guardada no Store
+type Run struct {
+	ID          string       `json:"id"`
+	Trigger     string       `json:"trigger"` // schedule | manual
+	StartedAt   time.Time    `json:"startedAt"`
+	FinishedAt  time.Time    `json:"finishedAt"`
+	Baseline    string       `json:"baseline"`
+	Target      string       `json:"target"`
+	Folders     []string     `json:"folders"`
+	Diverged    bool         `json:"diverged"`
+	Divergences []Divergence `json:"divergences"`
+	// Fingerprint identifica o conjunto de divergências (só notificamos quando muda)
+	Fingerprint  string            `json:"fingerprint,omitempty"`
+	Notified     []string          `json:"notified"`
+	NotifyErrors map[string]string `json:"notifyErrors,omitempty"`
+	Error        string            `json:"error,omitempty"`
+	Report       *Report           `json:"report,omitempty"`
+}
+
+// Scheduler calcula o drift periodicamente (cron) e notifica quando o target diverge do baseline
+type Scheduler struct {
+	cfg       *config.Config
+	store     *Store
+	notifiers []notify.Notifier
+	cron      *cron.Cron
+
+	running sync.Mutex
+	mu      sync.Mutex
+	lastFP  string // fingerprint da última notificação enviada
+}
+
+// NewScheduler valida a config (cron, ambientes) mas não inicia nada
+func NewScheduler(cfg *config.Config, store *Store, notifiers []notify.Notifier) (*Scheduler, error) {
+	for _, id := range []string{cfg.Drift.BaselineEnv, cfg.Drift.TargetEnv} {
+		if cfg.GetEnvironment(id) == nil {
+			return nil, fmt.Errorf("drift: unknown env: %s", id)
+		}
+	}
+	if _, err := ResolveEnvironments(cfg, cfg.Drift.Envs); err != nil {
+		return nil, fmt.Errorf("drift: %w", err)
+	}
+
+	s := &Scheduler{cfg: cfg, store: store, notifiers: notifiers}
+	// só a última notificação enviada vale: run sem notificação não pode calar a próxima
+	if latest := store.Get("latest"); latest != nil && len(latest.Notified) > 0 {
+		s.lastFP = latest.Fingerprint
+	}
+
+	if cfg.Drift.Enabled() {
+		s.cron = cron.New()
+		if _, err := s.cron.AddFunc(cfg.Drift.Schedule, s.scheduled); err != nil {
+			return nil, fmt.Errorf("drift: invalid DRIFT_SCHEDULE %q: %w", cfg.Drift.Schedule, err)
+		}
+	}
+	return s, nil
+}
+
+// Start liga o cron (no-op sem DRIFT_SCHEDULE)
+func (s *Scheduler) Start() {
+	if s.cron != nil {
+		s.cron.Start()
+	}
+}
+
+// Stop desliga o cron e espera a execução em andamento (até ctx expirar)
+func (s *Scheduler) Stop(ctx context.Context) {
+	if s.cron == nil {
+		return
+	}
+	select {
+	case <-s.cron.Stop().Done():
+	case <-ctx.Done():
+	}
+}
+
+// Store expõe as execuções guardadas
+func (s *Scheduler) Store() *Store {
+	return s.store
+}
+
+func (s *Scheduler) scheduled() {
+	if _, err := s.Run(context.Background(), "schedule"); err != nil && !errors.Is(err, ErrRunning) {
+		slog.Error("scheduled drift run failed", "error", err)
+	}
+}
+
+// Run executa agora (uma por vez), guarda o resultado e notifica se necessário
+func (s *Scheduler) Run(parent context.Context, trigger string) (*Run, error) {
+	if !s.running.TryLock() {
+		return nil, ErrRunning
+	}
+	defer s.running.Unlock()
+
+	envs, err := ResolveEnvironments(s.cfg, s.envIDs())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(parent), runTimeout)
+	defer cancel()
+
+	run := &Run{
+		ID:          time.Now().UTC().Format("20060102T150405Z") + "-" + logging.NewID()[:8],
+		Trigger:     trigger,
+		StartedAt:   time.Now().UTC(),
+		Baseline:    s.cfg.Drift.BaselineEnv,
+		Target:      s.cfg.Drift.TargetEnv,
+		Folders:     s.cfg.Drift.Folders,
+		Divergences: []Divergence{},
+		Notified:    []string{},
+	}
+	if run.Folders == nil {
+		run.Folders = []string{}
+	}
+	ctx = logging.WithJobID(ctx, run.ID)
+	slog.InfoContext(ctx, "drift run started", "trigger", trigger, "baseline", run.Baseline, "target", run.Target)
+
+	run.Report = Compute(ctx, envs, s.cfg.Drift.OrgID, run.Folders)
+	if msg := unavailable(run.Report, run.Baseline, run.Target); msg != "" {
+		run.Error = msg
+	} else {
+		run.Divergences = divergences(run.Report, run.Baseline, run.Target)
+		run.Diverged = len(run.Divergences) > 0
+		run.Fingerprint = fingerprint(run.Divergences)
+	}
+	run.FinishedAt = time.Now().UTC()
+
+	s.notify(ctx, run)
+
+	if err := s.store.Add(run); err != nil {
+		slog.ErrorContext(ctx, "drift run not persisted", "error", err)
+	}
+
+	slog.InfoContext(ctx, "drift run finished",
+		"diverged", run.Diverged,
+		"divergences", len(run.Divergences),
+		"notified", run.Notified,
+		"error", run.Error,
+		"duration_ms", run.FinishedAt.Sub(run.StartedAt).Milliseconds(),
+	)
+	return run, nil
+}
+
+// envIDs garante que baseline e target estão entre os ambientes comparados
+func (s *Scheduler) envIDs() []string {
+	ids := s.cfg.Drift.Envs
+	if len(ids) == 0 {
+		return nil // todos
+	}
+	out := append([]string{}, ids...)
+	for _, id := range []string{s.cfg.Drift.BaselineEnv, s.cfg.Drift.TargetEnv} {
+		found := false
+		for _, v := range out {
+			found = found || v == id
+		}
+		if !found {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// notify só dispara quando há divergência nova (fingerprint diferente da última notificada)
+func (s *Scheduler) notify(ctx context.Context, run *Run) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !run.Diverged {
+		// só um run bem-sucedido prova convergência: ambiente fora do ar (run.Error)
+		// não pode zerar o fingerprint e fazer a mesma divergência notificar de novo
+		if run.Error == "" {
+			s.lastFP = "" // voltou a convergir: a próxima divergência notifica de novo
+		}
+		return
+	}
+	if run.Fingerprint == s.lastFP || len(s.notifiers) == 0 {
+		return
+	}
+
+	msg := notify.Message{
+		Subject: fmt.Sprintf("[Dashboard Transporter] %s diverges from %s (%d dashboard(s))", strings.ToUpper(run.Target), strings.ToUpper(run.Baseline), len(run.Divergences)),
+		Text:    divergenceText(run),
+		Data:    runSummary(run),
+	}
+
+	for _, n := range s.notifiers {
+		if err := n.Notify(ctx, msg); err != nil {
+			if run.NotifyErrors == nil {
+				run.NotifyErrors = map[string]string{}
+			}
+			run.NotifyErrors[n.Name()] = err.Error()
+			slog.WarnContext(ctx, "drift notification failed", "channel", n.Name(), "error", err)
+			continue
+		}
+		run.Notified = append(run.Notified, n.Name())
+	}
+	if len(run.Notified) > 0 {
+		s.lastFP = run.Fingerprint
+	}
+}
+
+// unavailable: sem baseline ou target não dá p/ afirmar divergência
+func unavailable(rep *Report, envs ...string) string {
+	for _, e := range rep.Errors {
+		if e.UID != "" {
+			continue
+		}
+		for _, id := range envs {
+			if e.Env == id {
+				return fmt.Sprintf("env %s unavailable: %s", id, e.Message)
+			}
+		}
+	}
+	return ""
+}
+
+func divergences(rep *Report, baseline, target string) []Divergence {
+	out := []Divergence{}
+	for _, e := range rep.Dashboards {
+		b, t := e.Environments[baseline], e.Environments[target]
+		if b.Error != "" || t.Error != "" {
+			continue // falha de leitura não prova divergência
+		}
+		d := Divergence{
+			UID:             e.UID,
+			Title:           e.Title,
+			BaselineVersion: b.Version,
+			TargetVersion:   t.Version,
+			BaselineFolder:  b.FolderPath,
+			TargetFolder:    t.FolderPath,
+		}
+		switch {
+		case b.Present && t.Present && b.Hash != t.Hash:
+			d.Kind = KindChanged
+		case b.Present && !t.Present:
+			d.Kind = KindMissingInTarget
+		case !b.Present && t.Present:
+			d.Kind = KindOnlyInTarget
+		default:
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+func fingerprint(divs []Divergence) string {
+	if len(divs) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(divs))
+	for _, d := range divs {
+		keys = append(keys, fmt.Sprintf("%s:%s:%d:%d", d.UID, d.Kind, d.BaselineVersion, d.TargetVersion))
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func divergenceText(run *Run) string {
+	var b strings.Builder
+	for _, d := range run.Divergences {
+		switch d.Kind {
+		case KindChanged:
+			fmt.Fprintf(&b, "- %s (%s): content differs (%s v%d, %s v%d)\n", d.Title, d.UID, run.Baseline, d.BaselineVersion, run.Target, d.TargetVersion)
+		case KindMissingInTarget:
+			fmt.Fprintf(&b, "- %s (%s): missing in %s\n", d.Title, d.UID, run.Target)
+		case KindOnlyInTarget:
+			fmt.Fprintf(&b, "- %s (%s): only in %s (not in %s)\n", d.Title, d.UID, run.Target, run.Baseline)
+		}
+	}
+	fmt.Fprintf(&b, "\nRun %s at %s", run.ID, run.FinishedAt.Format(time.RFC3339))
+	return b.String()
+}
+
+// runSummary é o Run sem o relatório completo (vai no payload do webhook genérico)
+func runSummary(run *Run) Run {
+	out := *run
+	out.Report = nil
+	return out
+}
@@ -0,0 +1,117 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Store guarda as últimas execuções agendadas (memória + JSON em disco, se configurado)
+type Store struct {
+	mu    sync.RWMutex
+	dir   string
+	limit int
+	runs  []*Run // mais antiga primeiro
+}
+
+// NewStore cria o store; com dir, recarrega as execuções já persistidas
+func NewStore(dir string, limit int) (*Store, error) {
+	s := &Store{dir: dir, limit: limit}
+	if dir == "" {
+		return s, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("drift store: %w", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files) // nome começa com o timestamp => ordem cronológica
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			slog.Warn("drift store: skipping unreadable run", "file", f, "error", err)
+			continue
+		}
+		var run Run
+		if err := json.Unmarshal(b, &run); err != nil {
+			slog.Warn("drift store: skipping invalid run", "file", f, "error", err)
+			continue
+		}
+		s.runs = append(s.runs, &run)
+	}
+	s.trim()
+	return s, nil
+}
+
+// Add guarda a execução (e descarta as mais antigas além do limite)
+func (s *Store) Add(run *Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.runs = append(s.runs, run)
+	s.trim()
+
+	if s.dir == "" {
+		return nil
+	}
+	b, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(run.ID), b, 0o644)
+}
+
+// List devolve as execuções, mais recente primeiro
+func (s *Store) List() []*Run {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]*Run, 0, len(s.runs))
+	for i := len(s.runs) - 1; i >= 0; i-- {
+		out = append(out, s.runs[i])
+	}
+	return out
+}
+
+// Get busca pelo id ("latest" = a mais recente); nil se não existir
+func (s *Store) Get(id string) *Run {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if id == "latest" {
+		if len(s.runs) == 0 {
+			return nil
+		}
+		return s.runs[len(s.runs)-1]
+	}
+	for _, r := range s.runs {
+		if r.ID == id {
+			return r
+		}
+	}
+	return nil
+}
+
+// trim assume o lock (ou construção)
+func (s *Store) trim() {
+	for len(s.runs) > s.limit {
+		old := s.runs[0]
+		s.runs = s.runs[1:]
+		if s.dir != "" {
+			_ = os.Remove(s.path(old.ID))
+		}
+	}
+}
+
+func (s *Store) path(id string) string {
+	// id é gerado por nós, mas não custa blindar
+	return filepath.Join(s.dir, strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(id)+".json")
+}
@@ -0,0 +1,461 @@
+package gitrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	gittransport "github.com/go-git/go-git/v5/plumbing/transport"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/transport"
+)
+
+// DashboardsDir é a pasta (na raiz do repo) onde ficam os dashboards:
+// dashboards/<caminho do folder>/<uid>.json
+const DashboardsDir = "dashboards"
+
+const remoteName = "origin"
+
+// ErrNothingToCommit indica que o export não mudou nenhum arquivo
+var ErrNothingToCommit = errors.New("nothing to commit: dashboards already up to date in git")
+
+// Repo é a working copy local usada como origem/destino de dashboards.
+// Todas as operações são serializadas (a working copy é uma só).
+type Repo struct {
+	mu     sync.Mutex
+	cfg    config.GitConfig
+	repo   *git.Repository
+	remote bool
+}
+
+// File é um dashboard a ser gravado no repo
+type File struct {
+	UID        string
+	FolderPath string // "Time A/Projeto X" ("" = General)
+	Content    []byte
+}
+
+// CommitResult descreve o commit criado
+type CommitResult struct {
+	Commit string   `json:"commit"`
+	Branch string   `json:"branch"`
+	Files  []string `json:"files"`
+	Pushed bool     `json:"pushed"`
+}
+
+// Ref é uma branch ou tag do repo
+type Ref struct {
+	Name    string    `json:"name"`
+	Type    string    `json:"type"` // branch | tag
+	Commit  string    `json:"commit"`
+	Message string    `json:"message,omitempty"`
+	When    time.Time `json:"when,omitempty"`
+}
+
+// Open abre (ou cria) a working copy em cfg.Path. Com RemoteURL, clona
+// na primeira vez e usa o remote "origin" p/ fetch/push.
+func Open(cfg config.GitConfig) (*Repo, error) {
+	r, err := git.PlainOpen(cfg.Path)
+	switch {
+	case err == nil:
+	case errors.Is(err, git.ErrRepositoryNotExists):
+		r, err = initRepo(cfg)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("open git repo %s: %w", cfg.Path, err)
+	}
+
+	out := &Repo{cfg: cfg, repo: r}
+
+	if cfg.RemoteURL != "" {
+		if _, err := r.Remote(remoteName); errors.Is(err, git.ErrRemoteNotFound) {
+			_, err = r.CreateRemote(&gitconfig.RemoteConfig{Name: remoteName, URLs: []string{cfg.RemoteURL}})
+			if err != nil {
+				return nil, fmt.Errorf("create remote: %w", err)
+			}
+		}
+		out.remote = true
+	}
+
+	return out, nil
+}
+
+func initRepo(cfg config.GitConfig) (*git.Repository, error) {
+	if err := os.MkdirAll(cfg.Path, 0o755); err != nil {
+		return nil, err
+	}
+
+	if cfg.RemoteURL != "" {
+		r, err := git.PlainClone(cfg.Path, false, &git.CloneOptions{URL: cfg.RemoteURL, RemoteName: remoteName})
+		if err == nil {
+			slog.Info("git repository cloned", "path", cfg.Path, "remote", cfg.RemoteURL)
+			return r, nil
+		}
+		if !errors.Is(err, gittransport.ErrEmptyRemoteRepository) {
+			return nil, fmt.Errorf("clone %s: %w", cfg.RemoteURL, err)
+		}
+		// remote vazio: clone falha, mas dá p/ começar local e fazer push depois
+		_ = os.RemoveAll(filepath.Join(cfg.Path, ".git"))
+	}
+
+	r, err := git.PlainInit(cfg.Path, false)
+	if err != nil {
+		return nil, fmt.Errorf("init git repo %s: %w", cfg.Path, err)
+	}
+	slog.Info("git repository initialized", "path", cfg.Path)
+	return r, nil
+}
+
+// DefaultBranch devolve a branch configurada
+func (r *Repo) DefaultBranch() string {
+	return r.cfg.Branch
+}
+
+// CommitDashboards grava os arquivos na branch (criando se não existir),
+// commita com a mensagem informada e faz push se houver remote.
+func (r *Repo) CommitDashboards(ctx context.Context, branch, message string, files []File) (*CommitResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if branch == "" {
+		branch = r.cfg.Branch
+	}
+	if err := validBranch(branch); err != nil {
+		return nil, err
+	}
+	// valida todos os caminhos antes de mexer no working copy
+	paths := make([]string, len(files))
+	for i, f := range files {
+		rel, err := dashboardPath(f.FolderPath, f.UID)
+		if err != nil {
+			return nil, err
+		}
+		paths[i] = rel
+	}
+
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.fetch(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.checkoutBranch(ctx, wt, branch); err != nil {
+		return nil, err
+	}
+
+	root := wt.Filesystem.Root()
+	existing, err := existingDashboardFiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	written := make([]string, 0, len(files))
+	for i, f := range files {
+		rel := paths[i]
+
+		// dashboard mudou de folder/título: remove o arquivo antigo do mesmo uid
+		for _, old := range existing[f.UID] {
+			if old != rel {
+				if err := os.Remove(filepath.Join(root, filepath.FromSlash(old))); err != nil && !os.IsNotExist(err) {
+					return nil, err
+				}
+			}
+		}
+
+		abs := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(abs, f.Content, 0o644); err != nil {
+			return nil, err
+		}
+		written = append(written, rel)
+	}
+
+	if err := wt.AddWithOptions(&git.AddOptions{All: true, Path: DashboardsDir}); err != nil {
+		return nil, fmt.Errorf("git add: %w", err)
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return nil, err
+	}
+	if status.IsClean() {
+		return nil, ErrNothingToCommit
+	}
+
+	sig := &object.Signature{Name: r.cfg.AuthorName, Email: r.cfg.AuthorEmail, When: time.Now()}
+	hash, err := wt.Commit(message, &git.CommitOptions{Author: sig, Committer: sig})
+	if err != nil {
+		return nil, fmt.Errorf("git commit: %w", err)
+	}
+
+	res := &CommitResult{Commit: hash.String(), Branch: branch, Files: written}
+
+	if r.remote {
+		refSpec := gitconfig.RefSpec("refs/heads/" + branch + ":refs/heads/" + branch)
+		err := r.repo.PushContext(ctx, &git.PushOptions{RemoteName: remoteName, RefSpecs: []gitconfig.RefSpec{refSpec}})
+		if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+			// commit fica local; o próximo export tenta o push de novo
+			return res, fmt.Errorf("git push: %w", err)
+		}
+		res.Pushed = true
+	}
+
+	slog.InfoContext(ctx, "git commit created", "commit", res.Commit, "branch", branch, "files", len(written), "pushed", res.Pushed)
+	return res, nil
+}
+
+// LoadDashboards lê os dashboards (por uid) do commit/tag/branch informado.
+// Devolve o conteúdo por uid e o hash do commit resolvido.
+func (r *Repo) LoadDashboards(ctx context.Context, ref string, uids []string) (map[string][]byte, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.fetch(ctx); err != nil {
+		return nil, "", err
+	}
+
+	commit, err := r.resolve(ref)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, "", err
+	}
+
+	want := make(map[string]bool, len(uids))
+	for _, u := range uids {
+		want[u] = true
+	}
+
+	out := map[string][]byte{}
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if !strings.HasPrefix(f.Name, DashboardsDir+"/") || path.Ext(f.Name) != ".json" {
+			return nil
+		}
+		uid := strings.TrimSuffix(path.Base(f.Name), ".json")
+		if !want[uid] {
+			return nil
+		}
+		rd, err := f.Reader()
+		if err != nil {
+			return err
+		}
+		defer rd.Close()
+		b, err := io.ReadAll(rd)
+		if err != nil {
+			return err
+		}
+		out[uid] = b
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return out, commit.Hash.String(), nil
+}
+
+// Refs lista branches (locais e do origin) e tags
+func (r *Repo) Refs(ctx context.Context) ([]Ref, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.fetch(ctx); err != nil {
+		return nil, err
+	}
+
+	refs, err := r.repo.References()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Ref
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name()
+		var item Ref
+		switch {
+		case name.IsBranch():
+			item = Ref{Name: name.Short(), Type: "branch"}
+		case name.IsRemote():
+			item = Ref{Name: name.Short(), Type: "branch"}
+		case name.IsTag():
+			item = Ref{Name: name.Short(), Type: "tag"}
+		default:
+			return nil
+		}
+
+		commit, err := r.resolve(name.String())
+		if err != nil {
+			return nil
+		}
+		item.Commit = commit.Hash.String()
+		item.Message = firstLine(commit.Message)
+		item.When = commit.Author.When
+		out = append(out, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Type != out[j].Type {
+			return out[i].Type < out[j].Type
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+func (r *Repo) fetch(ctx context.Context) error {
+	if !r.remote {
+		return nil
+	}
+	err := r.repo.FetchContext(ctx, &git.FetchOptions{RemoteName: remoteName, Tags: git.AllTags})
+	if err == nil || errors.Is(err, git.NoErrAlreadyUpToDate) || errors.Is(err, gittransport.ErrEmptyRemoteRepository) {
+		return nil
+	}
+	return fmt.Errorf("git fetch: %w", err)
+}
+
+// resolve aceita hash (curto ou completo), tag, branch local ou origin/<branch>
+func (r *Repo) resolve(ref string) (*object.Commit, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, errors.New("empty git ref")
+	}
+
+	candidates := []string{ref}
+	if r.remote && !strings.HasPrefix(ref, "refs/") {
+		candidates = append(candidates, remoteName+"/"+ref)
+	}
+
+	for _, c := range candidates {
+		hash, err := r.repo.ResolveRevision(plumbing.Revision(c))
+		if err != nil {
+			continue
+		}
+		commit, err := r.repo.CommitObject(*hash)
+		if err != nil {
+			// tag anotada aponta p/ objeto tag
+			if tag, terr := r.repo.TagObject(*hash); terr == nil {
+				return tag.Commit()
+			}
+			continue
+		}
+		return commit, nil
+	}
+	return nil, fmt.Errorf("git ref not found: %s", ref)
+}
+
+// checkoutBranch deixa a working copy na branch, criando a partir do
+// origin/<branch> (se existir), do HEAD atual ou — repo vazio — apontando o HEAD.
+func (r *Repo) checkoutBranch(ctx context.Context, wt *git.Worktree, branch string) error {
+	local := plumbing.NewBranchReferenceName(branch)
+	remoteRef := plumbing.NewRemoteReferenceName(remoteName, branch)
+
+	if _, err := r.repo.Reference(local, true); err == nil {
+		if err := wt.Checkout(&git.CheckoutOptions{Branch: local, Force: true}); err != nil {
+			return fmt.Errorf("git checkout %s: %w", branch, err)
+		}
+		if r.remote {
+			if _, err := r.repo.Reference(remoteRef, true); err == nil {
+				err := wt.PullContext(ctx, &git.PullOptions{RemoteName: remoteName, ReferenceName: local, SingleBranch: true})
+				if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+					return fmt.Errorf("git pull %s: %w", branch, err)
+				}
+			}
+		}
+		return nil
+	}
+
+	if ref, err := r.repo.Reference(remoteRef, true); err == nil {
+		return wt.Checkout(&git.CheckoutOptions{Branch: local, Hash: ref.Hash(), Create: true, Force: true})
+	}
+
+	if head, err := r.repo.Head(); err == nil {
+		return wt.Checkout(&git.CheckoutOptions{Branch: local, Hash: head.Hash(), Create: true, Force: true})
+	}
+
+	// repo sem nenhum commit: o primeiro commit cria a branch
+	return r.repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, local))
+}
+
+// existingDashboardFiles indexa por uid os .json já presentes em dashboards/
+func existingDashboardFiles(root string) (map[string][]string, error) {
+	out := map[string][]string{}
+	base := filepath.Join(root, DashboardsDir)
+
+	err := filepath.WalkDir(base, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || filepath.Ext(p) != ".json" {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		uid := strings.TrimSuffix(filepath.Base(p), ".json")
+		out[uid] = append(out[uid], filepath.ToSlash(rel))
+		return nil
+	})
+	return out, err
+}
+
+// dashboardPath monta dashboards/<folder>/<uid>.json com cada nível sanitizado.
+// uid fora do formato do Grafana ou caminho fora de dashboards/ é erro.
+func dashboardPath(folderPath, uid string) (string, error) {
+	if !transport.ValidDashboardUID(uid) {
+		return "", fmt.Errorf("invalid dashboard uid %q", uid)
+	}
+	if folderPath == "" {
+		folderPath = "General"
+	}
+	p := path.Join(DashboardsDir, transport.SafeFolderPath(folderPath), uid+".json")
+	if !strings.HasPrefix(p, DashboardsDir+"/") {
+		return "", fmt.Errorf("dashboard path %q escapes %s", p, DashboardsDir)
+	}
+	return p, nil
+}
+
+func validBranch(b string) error {
+	if strings.HasPrefix(b, "-") || strings.Contains(b, "..") || strings.ContainsAny(b, " ~^:?*[\\") || strings.HasSuffix(b, "/") || strings.HasSuffix(b, ".lock") {
+		return fmt.Errorf("invalid branch name: %q", b)
+	}
+	return nil
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,36 @@
+package gitrepo
+
+import "testing"
+
+func TestDashboardPath(t *testing.T) {
+	cases := []struct {
+		name       string
+		folderPath string
+		uid        string
+		want       string
+		wantErr    bool
+	}{
+		{"general", "", "abc", "dashboards/General/abc.json", false},
+		{"nested folder", "Time A/Projeto X", "abc-1_2", "dashboards/Time A/Projeto X/abc-1_2.json", false},
+		{"dot-dot folder", "../../etc", "abc", "dashboards/_/_/etc/abc.json", false},
+		{"dot-dot only", "..", "abc", "dashboards/_/abc.json", false},
+		{"unsafe chars", `a:b*c\d`, "abc", "dashboards/a_b_c_d/abc.json", false},
+		{"empty levels", "a//b/", "abc", "dashboards/a/_/b/_/abc.json", false},
+		{"uid with slash", "x", "a/b", "", true},
+		{"uid with dot-dot", "x", "..", "", true},
+		{"uid with space", "x", "a b", "", true},
+		{"empty uid", "x", "", "", true},
+		{"uid too long", "x", "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := dashboardPath(c.folderPath, c.uid)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, c.wantErr)
+			}
+			if got != c.want {
+				t.Errorf("dashboardPath(%q, %q) = %q, want %q", c.folderPath, c.uid, got, c.want)
+			}
+		})
+	}
+}
@@ -2,35 +2,52 @@ package grafana
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
 )
 
 // Client representa o cliente para API do Grafana
 type Client struct {
+	env      string // id do ambiente (label das métricas); vazio = "unknown"
 	baseURL  string
 	username string
 	password string
+	orgID    string // "" = org default do usuário
 	client   *http.Client
 }
 
 // NewClient cria um novo cliente Grafana
 func NewClient(baseURL, username, password string) *Client {
+	return newClient("", baseURL, username, password)
+}
+
+func newClient(envID, baseURL, username, password string) *Client {
 	return &Client{
+		env:      envID,
 		baseURL:  strings.TrimRight(baseURL, "/"),
 		username: username,
 		password: password,
-		client:   &http.Client{},
+		client:   HTTPClient(envID),
 	}
 }
 
-// do executa uma requisição HTTP para a API do Grafana
-func (c *Client) do(method, path string, body interface{}, out interface{}) error {
+// WithOrg devolve uma cópia do client que manda X-Grafana-Org-Id em toda chamada
+func (c *Client) WithOrg(orgID string) *Client {
+	cp := *c
+	cp.orgID = orgID
+	return &cp
+}
+
+// do executa uma requisição HTTP para a API do Grafana.
+// O ctx carrega cancelamento/timeout e o request id (propagado pelo transport).
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
 	var reqBodyReader *strings.Reader
 
 	if body != nil {
@@ -43,7 +60,7 @@ func (c *Client) do(method, path string, body interface{}, out interface{}) erro
 		reqBodyReader = strings.NewReader("")
 	}
 
-	req, err := http.NewRequest(method, c.baseURL+path, reqBodyReader)
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBodyReader)
 	if err != nil {
 		return err
 	}
@@ -52,8 +69,9 @@ func (c *Client) do(method, path string, body interface{}, out interface{}) erro
 
 	req.Header.Set("Authorization", "Basic "+auth)
 	req.Header.Set("Content-Type", "application/json")
-
-	log.Printf("[GRAFANA API] %s %s", method, c.baseURL+path)
+	if c.orgID != "" {
+		req.Header.Set("X-Grafana-Org-Id", c.orgID)
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -65,9 +83,7 @@ func (c *Client) do(method, path string, body interface{}, out interface{}) erro
 	bodyBytes, _ := io.ReadAll(resp.Body)
 
 	if resp.StatusCode >= 400 {
-		var e map[string]interface{}
-		_ = json.Unmarshal(bodyBytes, &e)
-		return fmt.Errorf("grafana api error (%d): %v", resp.StatusCode, e)
+		return NewAPIError(resp.StatusCode, bodyBytes)
 	}
 
 	// Re-criar o reader para decodificar
@@ -82,16 +98,74 @@ func (c *Client) do(method, path string, body interface{}, out interface{}) erro
 
 // DashboardSearchItem representa um item na lista de dashboards
 type DashboardSearchItem struct {
-	ID    int    `json:"id"`
-	UID   string `json:"uid"`
-	Title string `json:"title"`
+	ID          int      `json:"id"`
+	UID         string   `json:"uid"`
+	Title       string   `json:"title"`
+	URL         string   `json:"url,omitempty"` // relativo ("/d/<uid>/<slug>")
+	Tags        []string `json:"tags,omitempty"`
+	FolderUID   string   `json:"folderUid,omitempty"`
+	FolderTitle string   `json:"folderTitle,omitempty"`
+}
+
+// SearchQuery são os filtros do /api/search (todos opcionais)
+type SearchQuery struct {
+	Query      string
+	Tags       []string // AND entre as tags (comportamento do Grafana)
+	FolderUIDs []string
+	// Starred = favoritos do usuário das credenciais do ambiente
+	Starred bool
 }
 
+// tamanho da página ao paginar o /api/search (máximo aceito pelo Grafana)
+const searchPageSize = 5000
+
 // ListDashboards lista todos os dashboards
-func (c *Client) ListDashboards() ([]DashboardSearchItem, error) {
-	var out []DashboardSearchItem
-	err := c.do("GET", "/api/search?type=dash-db", nil, &out)
-	return out, err
+func (c *Client) ListDashboards(ctx context.Context) ([]DashboardSearchItem, error) {
+	return c.SearchDashboards(ctx, SearchQuery{})
+}
+
+// SearchDashboards pagina o /api/search?type=dash-db até o fim (sem o limite default do Grafana)
+func (c *Client) SearchDashboards(ctx context.Context, q SearchQuery) ([]DashboardSearchItem, error) {
+	qs := url.Values{}
+	qs.Set("type", "dash-db")
+	qs.Set("limit", fmt.Sprintf("%d", searchPageSize))
+	if q.Query != "" {
+		qs.Set("query", q.Query)
+	}
+	for _, t := range q.Tags {
+		qs.Add("tag", t)
+	}
+	for _, f := range q.FolderUIDs {
+		qs.Add("folderUIDs", f)
+	}
+	if q.Starred {
+		qs.Set("starred", "true")
+	}
+
+	out := []DashboardSearchItem{}
+	seen := map[string]bool{}
+	for page := 1; ; page++ {
+		qs.Set("page", fmt.Sprintf("%d", page))
+
+		var items []DashboardSearchItem
+		if err := c.do(ctx, "GET", "/api/search?"+qs.Encode(), nil, &items); err != nil {
+			return nil, err
+		}
+
+		added := 0
+		for _, it := range items {
+			// Grafana antigo ignora "page" e repete a 1ª página: para quando nada é novo
+			if seen[it.UID] {
+				continue
+			}
+			seen[it.UID] = true
+			out = append(out, it)
+			added++
+		}
+		if len(items) < searchPageSize || added == 0 {
+			return out, nil
+		}
+	}
 }
 
 // DashboardFullResponse representa a resposta completa da API do Grafana
@@ -100,16 +174,46 @@ type DashboardFullResponse struct {
 	Meta      map[string]interface{} `json:"meta"`
 }
 
+// FolderUID devolve meta.folderUid ("" = General)
+func (r *DashboardFullResponse) FolderUID() string {
+	v, _ := r.Meta["folderUid"].(string)
+	return v
+}
+
+// ID devolve o id numérico (meta.id; 0 = desconhecido)
+func (r *DashboardFullResponse) ID() int {
+	if v, ok := r.Meta["id"].(float64); ok && v > 0 {
+		return int(v)
+	}
+	v, _ := r.Dashboard["id"].(float64)
+	return int(v)
+}
+
+// FolderTitle devolve meta.folderTitle
+func (r *DashboardFullResponse) FolderTitle() string {
+	v, _ := r.Meta["folderTitle"].(string)
+	return v
+}
+
 // GetDashboardByUID obtém um dashboard pelo UID
-func (c *Client) GetDashboardByUID(uid string) (map[string]interface{}, error) {
+func (c *Client) GetDashboardByUID(ctx context.Context, uid string) (map[string]interface{}, error) {
+	response, err := c.GetDashboardFull(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return response.Dashboard, nil
+}
+
+// GetDashboardFull obtém dashboard + meta (folder, versão, etc.) pelo UID
+func (c *Client) GetDashboardFull(ctx context.Context, uid string) (*DashboardFullResponse, error) {
 	var response DashboardFullResponse
-	err := c.do("GET", "/api/dashboards/uid/"+uid, nil, &response)
+	err := c.do(ctx, "GET", "/api/dashboards/uid/"+url.PathEscape(uid), nil, &response)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Printf("[GRAFANA API] Dashboard %s found, title: %v", uid, response.Dashboard["title"])
-	return response.Dashboard, nil
+	slog.DebugContext(ctx, "dashboard found", "env", c.env, "uid", uid, "title", response.Dashboard["title"])
+	return &response, nil
 }
 
 // sanitizeDashboardForImport evita conflitos de ID (folder x dashboard) no destino.
@@ -138,7 +242,7 @@ func sanitizeDashboardForImport(src map[string]interface{}) map[string]interface
 }
 
 // ImportDashboard importa um dashboard
-func (c *Client) ImportDashboard(dashboard map[string]interface{}, folderUID string) (string, error) {
+func (c *Client) ImportDashboard(ctx context.Context, dashboard map[string]interface{}, folderUID string) (string, error) {
 	safeDash := sanitizeDashboardForImport(dashboard)
 
 	payload := map[string]interface{}{
@@ -151,8 +255,7 @@ func (c *Client) ImportDashboard(dashboard map[string]interface{}, folderUID str
 		payload["folderUid"] = folderUID
 	}
 
-	log.Printf("[GRAFANA API] Importing dashboard, title: %v, folder: %s",
-		safeDash["title"], folderUID)
+	slog.InfoContext(ctx, "importing dashboard", "env", c.env, "title", safeDash["title"], "folder", folderUID)
 
 	var resp struct {
 		UID    string `json:"uid"`
@@ -160,43 +263,22 @@ func (c *Client) ImportDashboard(dashboard map[string]interface{}, folderUID str
 		Title  string `json:"title"`
 	}
 
-	err := c.do("POST", "/api/dashboards/db", payload, &resp)
+	err := c.do(ctx, "POST", "/api/dashboards/db", payload, &resp)
 	if err != nil {
-		log.Printf("[GRAFANA API] Import failed: %v", err)
+		slog.ErrorContext(ctx, "dashboard import failed", "env", c.env, "title", safeDash["title"], "error", err)
 		return "", err
 	}
 
-	log.Printf("[GRAFANA API] Import successful: %s (title: %s)", resp.UID, resp.Title)
+	slog.InfoContext(ctx, "dashboard imported", "env", c.env, "uid", resp.UID, "title", resp.Title)
 	return resp.UID, nil
 }
 
 // GetUserID obtém o ID de um usuário pelo login/email
-func (c *Client) GetUserID(login string) (int, error) {
+func (c *Client) GetUserID(ctx context.Context, login string) (int, error) {
 	var resp struct {
 		ID int `json:"id"`
 	}
 
-	err := c.do("GET", "/api/users/lookup?loginOrEmail="+login, nil, &resp)
+	err := c.do(ctx, "GET", "/api/users/lookup?loginOrEmail="+url.QueryEscape(login), nil, &resp)
 	return resp.ID, err
 }
-
-// SetDashboardPermissions define permissões para um dashboard
-func (c *Client) SetDashboardPermissions(dashboardUID string, userID int) error {
-	payload := map[string]interface{}{
-		"items": []map[string]interface{}{
-			{
-				"userId":     userID,
-				"permission": 2, // Editor = 2, View = 1, Admin = 4
-			},
-		},
-	}
-
-	log.Printf("[GRAFANA API] Setting permissions for dashboard %s, user ID: %d", dashboardUID, userID)
-
-	return c.do(
-		"POST",
-		"/api/dashboards/uid/"+dashboardUID+"/permissions",
-		payload,
-		nil,
-	)
-}
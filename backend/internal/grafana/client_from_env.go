@@ -28,5 +28,11 @@ func NewClientFromEnv(cfg *config.Config, envID string) (*Client, error) {
 		return nil, fmt.Errorf("environment %s missing PASSWORD", envID)
 	}
 
-	return NewClient(env.URL, env.User, env.Password), nil
+	return ClientForEnvironment(env), nil
+}
+
+// ClientForEnvironment cria o client sem validar credenciais
+// (útil p/ probes, onde credencial faltando vira "down" e não erro).
+func ClientForEnvironment(env *config.Environment) *Client {
+	return newClient(env.ID, env.URL, env.User, env.Password)
 }
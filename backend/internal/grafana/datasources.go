@@ -0,0 +1,22 @@
+package grafana
+
+import (
+	"context"
+)
+
+// DataSource representa um item de GET /api/datasources
+type DataSource struct {
+	ID        int    `json:"id"`
+	UID       string `json:"uid"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	TypeName  string `json:"typeName"`
+	IsDefault bool   `json:"isDefault"`
+}
+
+// ListDataSources lista os datasources da org
+func (c *Client) ListDataSources(ctx context.Context) ([]DataSource, error) {
+	var out []DataSource
+	err := c.do(ctx, "GET", "/api/datasources", nil, &out)
+	return out, err
+}
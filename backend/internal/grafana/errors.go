@@ -0,0 +1,67 @@
+package grafana
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"dashboard-transporter/internal/logging"
+)
+
+// tamanho máximo do body que vai parar numa mensagem de erro
+const maxErrorBody = 200
+
+// APIError é um erro HTTP >= 400 devolvido pelo Grafana
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("grafana api error (%d)", e.StatusCode)
+	}
+	return fmt.Sprintf("grafana api error (%d): %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound indica um 404 do Grafana
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == 404
+}
+
+// NewAPIError monta o erro a partir do body da resposta
+func NewAPIError(status int, body []byte) *APIError {
+	return &APIError{StatusCode: status, Message: ErrorMessage(body)}
+}
+
+// ErrorMessage extrai só o "message" do JSON de erro do Grafana; se não for JSON,
+// devolve o início do body. Sempre passa pelo Redact antes do corte (nunca ecoa o body inteiro).
+func ErrorMessage(body []byte) string {
+	var e struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	msg := ""
+	if err := json.Unmarshal(body, &e); err == nil {
+		msg = e.Message
+		if msg == "" {
+			msg = e.Error
+		}
+	} else {
+		msg = strings.TrimSpace(string(body))
+	}
+
+	// redact antes de cortar: segredo partido no corte não casaria com os regex
+	msg = logging.Redact(msg)
+	if len(msg) > maxErrorBody {
+		cut := maxErrorBody
+		for cut > 0 && !utf8.RuneStart(msg[cut]) {
+			cut--
+		}
+		msg = msg[:cut] + "..."
+	}
+	return msg
+}
@@ -1,6 +1,7 @@
 package grafana
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -9,19 +10,20 @@ import (
 )
 
 type FolderItem struct {
-	UID      string `json:"uid"`
-	Title    string `json:"title"`
+	UID       string `json:"uid"`
+	Title     string `json:"title"`
 	ParentUID string `json:"parentUid,omitempty"` // pode vir vazio dependendo da versão
 }
 
 type FolderOut struct {
-	UID   string `json:"uid"`
-	Title string `json:"title"` // aqui vai vir "Time A/Projeto X"
+	UID       string `json:"uid"`
+	Title     string `json:"title"` // aqui vai vir "Time A/Projeto X"
+	ParentUID string `json:"parentUid,omitempty"`
 }
 
 // listFoldersPage chama:
 // GET /api/folders?parentUid=<uid>&page=<n>&limit=<n>
-func (c *Client) listFoldersPage(parentUid string, page, limit int) ([]FolderItem, error) {
+func (c *Client) listFoldersPage(ctx context.Context, parentUid string, page, limit int) ([]FolderItem, error) {
 	qs := url.Values{}
 	if strings.TrimSpace(parentUid) != "" {
 		qs.Set("parentUid", parentUid)
@@ -33,7 +35,7 @@ func (c *Client) listFoldersPage(parentUid string, page, limit int) ([]FolderIte
 
 	// a resposta é um array
 	var out []FolderItem
-	if err := c.do("GET", path, nil, &out); err != nil {
+	if err := c.do(ctx, "GET", path, nil, &out); err != nil {
 		return nil, err
 	}
 	return out, nil
@@ -43,7 +45,7 @@ func (c *Client) listFoldersPage(parentUid string, page, limit int) ([]FolderIte
 // General (uid="")
 // Time A
 // Time A/Projeto X
-func (c *Client) ListFoldersFlat() ([]FolderOut, error) {
+func (c *Client) ListFoldersFlat(ctx context.Context) ([]FolderOut, error) {
 	// Sempre inclui General como opção
 	result := []FolderOut{
 		{UID: "", Title: "General"},
@@ -63,7 +65,7 @@ func (c *Client) ListFoldersFlat() ([]FolderOut, error) {
 		// paginação
 		page := 1
 		for {
-			items, err := c.listFoldersPage(parentUid, page, limit)
+			items, err := c.listFoldersPage(ctx, parentUid, page, limit)
 			if err != nil {
 				return err
 			}
@@ -88,8 +90,9 @@ func (c *Client) ListFoldersFlat() ([]FolderOut, error) {
 				}
 
 				result = append(result, FolderOut{
-					UID:   f.UID,
-					Title: fullPath,
+					UID:       f.UID,
+					Title:     fullPath,
+					ParentUID: parentUid,
 				})
 
 				// desce pros filhos
@@ -125,9 +128,34 @@ func (c *Client) ListFoldersFlat() ([]FolderOut, error) {
 	return result, nil
 }
 
+// DescendantFolderUIDs devolve rootUID + todos os folders abaixo dele
+// (a partir do resultado do ListFoldersFlat)
+func DescendantFolderUIDs(folders []FolderOut, rootUID string) map[string]bool {
+	children := map[string][]string{}
+	for _, f := range folders {
+		if f.UID != "" {
+			children[f.ParentUID] = append(children[f.ParentUID], f.UID)
+		}
+	}
+
+	out := map[string]bool{rootUID: true}
+	queue := []string{rootUID}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, c := range children[cur] {
+			if !out[c] {
+				out[c] = true
+				queue = append(queue, c)
+			}
+		}
+	}
+	return out
+}
+
 // (opcional) helper pra debug rápido
-func (c *Client) DebugFoldersJSON() (string, error) {
-	f, err := c.ListFoldersFlat()
+func (c *Client) DebugFoldersJSON(ctx context.Context) (string, error) {
+	f, err := c.ListFoldersFlat(ctx)
 	if err != nil {
 		return "", err
 	}
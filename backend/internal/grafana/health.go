@@ -0,0 +1,38 @@
+package grafana
+
+import (
+	"context"
+)
+
+// HealthResponse representa a resposta de GET /api/health
+type HealthResponse struct {
+	Database string `json:"database"`
+	Version  string `json:"version"`
+	Commit   string `json:"commit"`
+}
+
+// SignedInUser representa a resposta de GET /api/user
+type SignedInUser struct {
+	ID      int    `json:"id"`
+	Login   string `json:"login"`
+	Email   string `json:"email"`
+	IsAdmin bool   `json:"isGrafanaAdmin"`
+}
+
+// Health consulta o /api/health (não exige autenticação no Grafana)
+func (c *Client) Health(ctx context.Context) (*HealthResponse, error) {
+	var out HealthResponse
+	if err := c.do(ctx, "GET", "/api/health", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CurrentUser valida as credenciais configuradas chamando GET /api/user
+func (c *Client) CurrentUser(ctx context.Context) (*SignedInUser, error) {
+	var out SignedInUser
+	if err := c.do(ctx, "GET", "/api/user", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,78 @@
+package grafana
+
+import (
+	"context"
+	"net/url"
+)
+
+// LibraryElement representa um library panel (GET /api/library-elements/<uid>)
+type LibraryElement struct {
+	ID        int            `json:"id,omitempty"`
+	UID       string         `json:"uid"`
+	Name      string         `json:"name"`
+	Kind      int            `json:"kind"`
+	Type      string         `json:"type"`
+	FolderUID string         `json:"folderUid,omitempty"`
+	Version   int            `json:"version,omitempty"`
+	Model     map[string]any `json:"model"`
+}
+
+// GetLibraryElement busca um library panel pelo uid
+func (c *Client) GetLibraryElement(ctx context.Context, uid string) (*LibraryElement, error) {
+	var resp libraryElementResponse
+	if err := c.do(ctx, "GET", "/api/library-elements/"+url.PathEscape(uid), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Result, nil
+}
+
+// libraryPanelKind é o kind de library panel (2 = variable, não usado aqui)
+const libraryPanelKind = 1
+
+type libraryElementResponse struct {
+	Result LibraryElement `json:"result"`
+}
+
+// CreateLibraryElement cria o library panel mantendo o uid da origem
+func (c *Client) CreateLibraryElement(ctx context.Context, el LibraryElement, folderUID string) (*LibraryElement, error) {
+	kind := el.Kind
+	if kind == 0 {
+		kind = libraryPanelKind
+	}
+	payload := map[string]any{
+		"uid":       el.UID,
+		"name":      el.Name,
+		"kind":      kind,
+		"model":     el.Model,
+		"folderUid": folderUID,
+	}
+
+	var resp libraryElementResponse
+	if err := c.do(ctx, "POST", "/api/library-elements", payload, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Result, nil
+}
+
+// UpdateLibraryElement sobrescreve nome/model/folder; version é a versão ATUAL no destino
+// (o Grafana recusa com 412 se alguém alterou no meio)
+func (c *Client) UpdateLibraryElement(ctx context.Context, el LibraryElement, folderUID string, version int) (*LibraryElement, error) {
+	kind := el.Kind
+	if kind == 0 {
+		kind = libraryPanelKind
+	}
+	payload := map[string]any{
+		"uid":       el.UID,
+		"name":      el.Name,
+		"kind":      kind,
+		"model":     el.Model,
+		"folderUid": folderUID,
+		"version":   version,
+	}
+
+	var resp libraryElementResponse
+	if err := c.do(ctx, "PATCH", "/api/library-elements/"+url.PathEscape(el.UID), payload, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Result, nil
+}
@@ -0,0 +1,337 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// níveis de permissão de dashboard da API legada
+const (
+	PermissionView  = 1
+	PermissionEdit  = 2
+	PermissionAdmin = 4
+)
+
+// PermissionLevel converte "View" | "Edit" | "Admin" (sem caixa) no nível da API
+func PermissionLevel(name string) (int, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "view":
+		return PermissionView, true
+	case "edit":
+		return PermissionEdit, true
+	case "admin":
+		return PermissionAdmin, true
+	}
+	return 0, false
+}
+
+// PermissionName é o inverso de PermissionLevel ("View", "Edit", "Admin")
+func PermissionName(level int) string {
+	switch level {
+	case PermissionView:
+		return "View"
+	case PermissionEdit:
+		return "Edit"
+	case PermissionAdmin:
+		return "Admin"
+	}
+	return strconv.Itoa(level)
+}
+
+// DashboardPermission é um item de GET /api/dashboards/uid/{uid}/permissions
+// (a API de access-control é convertida p/ esse formato)
+type DashboardPermission struct {
+	UserID     int    `json:"userId"`
+	UserLogin  string `json:"userLogin"`
+	UserEmail  string `json:"userEmail"`
+	TeamID     int    `json:"teamId"`
+	Team       string `json:"team"`
+	Role       string `json:"role"`
+	Permission int    `json:"permission"`
+	// Inherited = vem do folder (não é do dashboard)
+	Inherited bool `json:"inherited"`
+}
+
+// PermissionsAPI é a API de permissões de dashboard usada num ambiente
+type PermissionsAPI string
+
+const (
+	// PermissionsAPIAccessControl = /api/access-control/dashboards/{uid}/... (Grafana 11+)
+	PermissionsAPIAccessControl PermissionsAPI = "access-control"
+	// PermissionsAPIUID = /api/dashboards/uid/{uid}/permissions (Grafana 9 e 10)
+	PermissionsAPIUID PermissionsAPI = "uid"
+	// PermissionsAPIID = /api/dashboards/id/{id}/permissions (Grafana < 9)
+	PermissionsAPIID PermissionsAPI = "id"
+)
+
+// PermissionsAPIFor escolhe a API pela versão do Grafana ("11.3.0", "10.4.2+security-01").
+// Versão vazia/ilegível = uid, que existe do 9 ao 11.
+func PermissionsAPIFor(version string) PermissionsAPI {
+	major, err := strconv.Atoi(strings.TrimLeft(strings.SplitN(version, ".", 2)[0], "v"))
+	switch {
+	case err != nil:
+		return PermissionsAPIUID
+	case major >= 11:
+		return PermissionsAPIAccessControl
+	case major >= 9:
+		return PermissionsAPIUID
+	default:
+		return PermissionsAPIID
+	}
+}
+
+// quanto tempo a API detectada vale: um upgrade do Grafana troca a API sem
+// restart do transporter, então a detecção é refeita de tempos em tempos
+const permissionsAPITTL = 10 * time.Minute
+
+type detectedAPI struct {
+	api PermissionsAPI
+	at  time.Time
+}
+
+// API detectada por URL do Grafana (baseURL -> detectedAPI)
+var permissionsAPIs sync.Map
+
+// DetectPermissionsAPI consulta o /api/health no máximo uma vez por permissionsAPITTL
+// por ambiente. Falha cai no uid e não fica no cache (tenta de novo na próxima).
+func (c *Client) DetectPermissionsAPI(ctx context.Context) PermissionsAPI {
+	if v, ok := permissionsAPIs.Load(c.baseURL); ok {
+		if d := v.(detectedAPI); time.Since(d.at) < permissionsAPITTL {
+			return d.api
+		}
+	}
+	h, err := c.Health(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "grafana version unknown, using uid permissions api", "env", c.env, "error", err)
+		return PermissionsAPIUID
+	}
+	api := PermissionsAPIFor(h.Version)
+	permissionsAPIs.Store(c.baseURL, detectedAPI{api: api, at: time.Now()})
+	slog.InfoContext(ctx, "dashboard permissions api detected", "env", c.env, "version", h.Version, "api", api)
+	return api
+}
+
+// GetDashboardPermissions lista as permissões do dashboard (inclui as herdadas do folder)
+// pela API que o ambiente suporta
+func (c *Client) GetDashboardPermissions(ctx context.Context, uid string) ([]DashboardPermission, error) {
+	switch c.DetectPermissionsAPI(ctx) {
+	case PermissionsAPIAccessControl:
+		return c.getAccessControlPermissions(ctx, uid)
+	case PermissionsAPIID:
+		id, err := c.dashboardID(ctx, uid)
+		if err != nil {
+			return nil, err
+		}
+		return c.getLegacyPermissions(ctx, fmt.Sprintf("/api/dashboards/id/%d/permissions", id))
+	default:
+		return c.getLegacyPermissions(ctx, "/api/dashboards/uid/"+url.PathEscape(uid)+"/permissions")
+	}
+}
+
+func (c *Client) getLegacyPermissions(ctx context.Context, path string) ([]DashboardPermission, error) {
+	var out []DashboardPermission
+	err := c.do(ctx, "GET", path, nil, &out)
+	return out, err
+}
+
+// accessControlPermission é um item de GET /api/access-control/dashboards/{uid}
+type accessControlPermission struct {
+	UserID           int    `json:"userId"`
+	UserLogin        string `json:"userLogin"`
+	TeamID           int    `json:"teamId"`
+	Team             string `json:"team"`
+	BuiltInRole      string `json:"builtInRole"`
+	Permission       string `json:"permission"` // "View" | "Edit" | "Admin"
+	IsManaged        bool   `json:"isManaged"`
+	IsInherited      bool   `json:"isInherited"`
+	IsServiceAccount bool   `json:"isServiceAccount"`
+}
+
+// getAccessControlPermissions converte p/ o formato legado. Só as gerenciadas do
+// próprio dashboard contam como não herdadas (as de roles fixas/custom não são copiáveis).
+func (c *Client) getAccessControlPermissions(ctx context.Context, uid string) ([]DashboardPermission, error) {
+	var items []accessControlPermission
+	if err := c.do(ctx, "GET", "/api/access-control/dashboards/"+url.PathEscape(uid), nil, &items); err != nil {
+		return nil, err
+	}
+	out := make([]DashboardPermission, 0, len(items))
+	for _, it := range items {
+		level, ok := PermissionLevel(it.Permission)
+		if !ok || it.IsServiceAccount {
+			continue
+		}
+		out = append(out, DashboardPermission{
+			UserID:     it.UserID,
+			UserLogin:  it.UserLogin,
+			TeamID:     it.TeamID,
+			Team:       it.Team,
+			Role:       it.BuiltInRole,
+			Permission: level,
+			Inherited:  it.IsInherited || !it.IsManaged,
+		})
+	}
+	return out, nil
+}
+
+// PermissionItem é um principal (user, team ou role) com o nível a conceder
+type PermissionItem struct {
+	UserID     int
+	TeamID     int
+	Role       string
+	Permission int
+}
+
+// SetDashboardPermissions concede os itens no dashboard sem apagar as permissões que
+// já existem (o principal que já tem permissão fica com o nível pedido).
+// Na API de access-control é uma chamada por principal; nas legadas, 1 GET + 1 POST.
+func (c *Client) SetDashboardPermissions(ctx context.Context, uid string, items []PermissionItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	api := c.DetectPermissionsAPI(ctx)
+	slog.InfoContext(ctx, "setting dashboard permissions", "env", c.env, "uid", uid, "api", api, "items", len(items))
+
+	switch api {
+	case PermissionsAPIAccessControl:
+		return c.setAccessControlPermissions(ctx, uid, items)
+	case PermissionsAPIID:
+		id, err := c.dashboardID(ctx, uid)
+		if err != nil {
+			return err
+		}
+		return c.setLegacyPermissions(ctx, fmt.Sprintf("/api/dashboards/id/%d/permissions", id), items)
+	default:
+		return c.setLegacyPermissions(ctx, "/api/dashboards/uid/"+url.PathEscape(uid)+"/permissions", items)
+	}
+}
+
+func (c *Client) setAccessControlPermissions(ctx context.Context, uid string, items []PermissionItem) error {
+	base := "/api/access-control/dashboards/" + url.PathEscape(uid)
+	var failed []string
+	for _, it := range items {
+		var path, who string
+		switch {
+		case it.UserID != 0:
+			path, who = fmt.Sprintf("%s/users/%d", base, it.UserID), fmt.Sprintf("user %d", it.UserID)
+		case it.TeamID != 0:
+			path, who = fmt.Sprintf("%s/teams/%d", base, it.TeamID), fmt.Sprintf("team %d", it.TeamID)
+		default:
+			path, who = base+"/builtInRoles/"+url.PathEscape(it.Role), "role "+it.Role
+		}
+		body := map[string]string{"permission": PermissionName(it.Permission)}
+		if err := c.do(ctx, "POST", path, body, nil); err != nil {
+			failed = append(failed, who+": "+err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("set permissions: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// setLegacyPermissions regrava a lista inteira (o POST legado sobrescreve tudo):
+// preserva as entradas próprias do dashboard e aplica os itens por cima
+func (c *Client) setLegacyPermissions(ctx context.Context, path string, items []PermissionItem) error {
+	current, err := c.getLegacyPermissions(ctx, path)
+	if err != nil {
+		return fmt.Errorf("get permissions: %w", err)
+	}
+
+	type principal struct {
+		userID, teamID int
+		role           string
+	}
+	key := func(it PermissionItem) principal {
+		switch {
+		case it.UserID != 0:
+			return principal{userID: it.UserID}
+		case it.TeamID != 0:
+			return principal{teamID: it.TeamID}
+		default:
+			return principal{role: it.Role}
+		}
+	}
+	wanted := map[principal]int{}
+	for _, it := range items {
+		wanted[key(it)] = it.Permission
+	}
+
+	out := make([]map[string]any, 0, len(current)+len(items))
+	item := func(pr principal, perm int) map[string]any {
+		switch {
+		case pr.userID != 0:
+			return map[string]any{"userId": pr.userID, "permission": perm}
+		case pr.teamID != 0:
+			return map[string]any{"teamId": pr.teamID, "permission": perm}
+		default:
+			return map[string]any{"role": pr.role, "permission": perm}
+		}
+	}
+
+	done := map[principal]bool{}
+	for _, p := range current {
+		if p.Inherited || (p.UserID == 0 && p.TeamID == 0 && p.Role == "") {
+			continue // herdadas do folder não vão no POST (viraria permissão própria)
+		}
+		pr := key(PermissionItem{UserID: p.UserID, TeamID: p.TeamID, Role: p.Role})
+		perm := p.Permission
+		if w, ok := wanted[pr]; ok {
+			perm = w
+			done[pr] = true
+		}
+		out = append(out, item(pr, perm))
+	}
+	for _, it := range items {
+		if pr := key(it); !done[pr] {
+			done[pr] = true
+			out = append(out, item(pr, it.Permission))
+		}
+	}
+
+	if err := c.do(ctx, "POST", path, map[string]any{"items": out}, nil); err != nil {
+		return fmt.Errorf("post permissions: %w", err)
+	}
+	return nil
+}
+
+// dashboardID busca o id numérico (só a API legada por id precisa dele)
+func (c *Client) dashboardID(ctx context.Context, uid string) (int, error) {
+	full, err := c.GetDashboardFull(ctx, uid)
+	if err != nil {
+		return 0, err
+	}
+	id := full.ID()
+	if id == 0 {
+		return 0, fmt.Errorf("dashboard %s has no numeric id", uid)
+	}
+	return id, nil
+}
+
+// Team é um item de GET /api/teams/search
+type Team struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// FindTeamByName procura o team pelo nome exato (nil, nil = não existe)
+func (c *Client) FindTeamByName(ctx context.Context, name string) (*Team, error) {
+	var resp struct {
+		Teams []Team `json:"teams"`
+	}
+	if err := c.do(ctx, "GET", "/api/teams/search?name="+url.QueryEscape(name), nil, &resp); err != nil {
+		return nil, err
+	}
+	for i := range resp.Teams {
+		if strings.EqualFold(resp.Teams[i].Name, name) {
+			return &resp.Teams[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,232 @@
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPermissionsAPIFor(t *testing.T) {
+	cases := []struct {
+		version string
+		want    PermissionsAPI
+	}{
+		{"11.3.0", PermissionsAPIAccessControl},
+		{"12.0.0-preview", PermissionsAPIAccessControl},
+		{"10.4.2+security-01", PermissionsAPIUID},
+		{"v9.0", PermissionsAPIUID},
+		{"9", PermissionsAPIUID},
+		{"8.5.27", PermissionsAPIID},
+		{"7.5.0", PermissionsAPIID},
+		{"", PermissionsAPIUID},
+		{"unknown", PermissionsAPIUID},
+	}
+	for _, c := range cases {
+		if got := PermissionsAPIFor(c.version); got != c.want {
+			t.Errorf("PermissionsAPIFor(%q) = %q, want %q", c.version, got, c.want)
+		}
+	}
+}
+
+// fakeGrafana guarda o que o client mandou p/ as APIs de permissão
+type fakeGrafana struct {
+	version string
+	current []DashboardPermission // GET das APIs legadas
+
+	mu          sync.Mutex
+	healthCalls int
+	posts       map[string]json.RawMessage // path -> body
+}
+
+func (f *fakeGrafana) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.URL.Path == "/api/health":
+		f.healthCalls++
+		_ = json.NewEncoder(w).Encode(HealthResponse{Version: f.version})
+	case r.URL.Path == "/api/dashboards/uid/abc" && r.Method == http.MethodGet:
+		_, _ = w.Write([]byte(`{"dashboard":{"uid":"abc","title":"A"},"meta":{"id":42}}`))
+	case r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(f.current)
+	case r.Method == http.MethodPost:
+		var body json.RawMessage
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if f.posts == nil {
+			f.posts = map[string]json.RawMessage{}
+		}
+		f.posts[r.URL.Path] = body
+		_, _ = w.Write([]byte(`{"message":"ok"}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeGrafana) setVersion(v string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.version = v
+}
+
+func (f *fakeGrafana) calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.healthCalls
+}
+
+func newFakeGrafana(t *testing.T, f *fakeGrafana) *Client {
+	t.Helper()
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return NewClient(srv.URL, "admin", "admin")
+}
+
+type legacyItem struct {
+	UserID     int    `json:"userId,omitempty"`
+	TeamID     int    `json:"teamId,omitempty"`
+	Role       string `json:"role,omitempty"`
+	Permission int    `json:"permission"`
+}
+
+func TestSetLegacyPermissionsMerge(t *testing.T) {
+	current := []DashboardPermission{
+		{UserID: 1, UserLogin: "admin", Permission: PermissionAdmin},
+		{TeamID: 3, Team: "Ops", Permission: PermissionView},
+		{Role: "Viewer", Permission: PermissionView},
+		{Role: "Editor", Permission: PermissionEdit, Inherited: true}, // do folder
+		{Permission: PermissionView},                                  // sem principal
+	}
+
+	cases := []struct {
+		name  string
+		items []PermissionItem
+		want  []legacyItem
+	}{
+		{
+			name:  "preserva as entradas próprias e adiciona as novas no fim",
+			items: []PermissionItem{{UserID: 7, Permission: PermissionEdit}, {Role: "Editor", Permission: PermissionView}},
+			want: []legacyItem{
+				{UserID: 1, Permission: PermissionAdmin},
+				{TeamID: 3, Permission: PermissionView},
+				{Role: "Viewer", Permission: PermissionView},
+				{UserID: 7, Permission: PermissionEdit},
+				{Role: "Editor", Permission: PermissionView},
+			},
+		},
+		{
+			name:  "principal existente fica com o nível pedido (mesmo menor)",
+			items: []PermissionItem{{TeamID: 3, Permission: PermissionEdit}, {UserID: 1, Permission: PermissionView}},
+			want: []legacyItem{
+				{UserID: 1, Permission: PermissionView},
+				{TeamID: 3, Permission: PermissionEdit},
+				{Role: "Viewer", Permission: PermissionView},
+			},
+		},
+		{
+			name:  "item repetido não duplica",
+			items: []PermissionItem{{UserID: 9, Permission: PermissionView}, {UserID: 9, Permission: PermissionView}},
+			want: []legacyItem{
+				{UserID: 1, Permission: PermissionAdmin},
+				{TeamID: 3, Permission: PermissionView},
+				{Role: "Viewer", Permission: PermissionView},
+				{UserID: 9, Permission: PermissionView},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f := &fakeGrafana{current: current}
+			client := newFakeGrafana(t, f)
+
+			if err := client.setLegacyPermissions(context.Background(), "/api/dashboards/uid/abc/permissions", c.items); err != nil {
+				t.Fatal(err)
+			}
+
+			var body struct {
+				Items []legacyItem `json:"items"`
+			}
+			if err := json.Unmarshal(f.posts["/api/dashboards/uid/abc/permissions"], &body); err != nil {
+				t.Fatalf("decode post: %v", err)
+			}
+			if !reflect.DeepEqual(body.Items, c.want) {
+				t.Errorf("items =\n%+v\nwant\n%+v", body.Items, c.want)
+			}
+		})
+	}
+}
+
+func TestSetDashboardPermissionsByAPI(t *testing.T) {
+	items := []PermissionItem{
+		{UserID: 7, Permission: PermissionEdit},
+		{TeamID: 3, Permission: PermissionView},
+		{Role: "Viewer", Permission: PermissionView},
+	}
+
+	t.Run("access-control", func(t *testing.T) {
+		f := &fakeGrafana{version: "11.3.0"}
+		if err := newFakeGrafana(t, f).SetDashboardPermissions(context.Background(), "abc", items); err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]string{
+			"/api/access-control/dashboards/abc/users/7":             `{"permission":"Edit"}`,
+			"/api/access-control/dashboards/abc/teams/3":             `{"permission":"View"}`,
+			"/api/access-control/dashboards/abc/builtInRoles/Viewer": `{"permission":"View"}`,
+		}
+		if len(f.posts) != len(want) {
+			t.Fatalf("posts = %v", f.posts)
+		}
+		for path, body := range want {
+			if string(f.posts[path]) != body {
+				t.Errorf("%s: body = %s, want %s", path, f.posts[path], body)
+			}
+		}
+	})
+
+	t.Run("uid", func(t *testing.T) {
+		f := &fakeGrafana{version: "10.4.2"}
+		if err := newFakeGrafana(t, f).SetDashboardPermissions(context.Background(), "abc", items); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := f.posts["/api/dashboards/uid/abc/permissions"]; !ok || len(f.posts) != 1 {
+			t.Errorf("posts = %v", f.posts)
+		}
+	})
+
+	t.Run("id", func(t *testing.T) {
+		f := &fakeGrafana{version: "8.5.0"}
+		if err := newFakeGrafana(t, f).SetDashboardPermissions(context.Background(), "abc", items); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := f.posts["/api/dashboards/id/42/permissions"]; !ok || len(f.posts) != 1 {
+			t.Errorf("posts = %v", f.posts)
+		}
+	})
+}
+
+func TestDetectPermissionsAPICacheExpires(t *testing.T) {
+	f := &fakeGrafana{version: "10.4.2"}
+	client := newFakeGrafana(t, f)
+	ctx := context.Background()
+
+	if got := client.DetectPermissionsAPI(ctx); got != PermissionsAPIUID {
+		t.Fatalf("api = %q", got)
+	}
+	f.setVersion("11.0.0") // upgrade do Grafana
+	if got := client.DetectPermissionsAPI(ctx); got != PermissionsAPIUID || f.calls() != 1 {
+		t.Fatalf("cached api = %q, health calls = %d", got, f.calls())
+	}
+
+	// detecção vencida: consulta de novo e pega a API nova
+	permissionsAPIs.Store(client.baseURL, detectedAPI{api: PermissionsAPIUID, at: time.Now().Add(-permissionsAPITTL - time.Second)})
+	if got := client.DetectPermissionsAPI(ctx); got != PermissionsAPIAccessControl || f.calls() != 2 {
+		t.Fatalf("after ttl api = %q, health calls = %d", got, f.calls())
+	}
+}
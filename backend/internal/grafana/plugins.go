@@ -0,0 +1,25 @@
+package grafana
+
+import (
+	"context"
+	"net/url"
+)
+
+// Plugin é um item de GET /api/plugins (inclui os plugins core)
+type Plugin struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"` // panel | datasource | app
+}
+
+// ListPlugins lista os plugins instalados; typ vazio = todos
+func (c *Client) ListPlugins(ctx context.Context, typ string) ([]Plugin, error) {
+	qs := url.Values{}
+	qs.Set("embedded", "0")
+	if typ != "" {
+		qs.Set("type", typ)
+	}
+	var out []Plugin
+	err := c.do(ctx, "GET", "/api/plugins?"+qs.Encode(), nil, &out)
+	return out, err
+}
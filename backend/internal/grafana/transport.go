@@ -0,0 +1,63 @@
+package grafana
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"dashboard-transporter/internal/logging"
+	"dashboard-transporter/internal/metrics"
+)
+
+// instrumentedTransport mede a latência de toda chamada ao Grafana por ambiente/endpoint,
+// propaga o X-Request-Id do contexto e loga a chamada (sem headers/credenciais).
+type instrumentedTransport struct {
+	env  string
+	next http.RoundTripper
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if id := logging.RequestID(ctx); id != "" {
+		// RoundTripper não deve alterar o request original
+		req = req.Clone(ctx)
+		req.Header.Set("X-Request-Id", id)
+	}
+
+	endpoint := metrics.GrafanaEndpoint(req.URL.Path)
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+
+	metrics.GrafanaRequestDuration.
+		WithLabelValues(t.env, req.Method, endpoint, code).
+		Observe(elapsed.Seconds())
+
+	slog.DebugContext(ctx, "grafana api call",
+		"env", t.env,
+		"method", req.Method,
+		"endpoint", endpoint,
+		"status", code,
+		"duration_ms", elapsed.Milliseconds(),
+	)
+
+	return resp, err
+}
+
+// HTTPClient devolve um *http.Client instrumentado para o ambiente envID.
+// Usar no lugar de http.DefaultClient quando a chamada ao Grafana é feita "na mão".
+func HTTPClient(envID string) *http.Client {
+	if envID == "" {
+		envID = "unknown"
+	}
+	return &http.Client{
+		Transport: &instrumentedTransport{env: envID, next: http.DefaultTransport},
+	}
+}
@@ -0,0 +1,70 @@
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DashboardVersion é um item do histórico de versões de um dashboard
+type DashboardVersion struct {
+	ID            int       `json:"id"`
+	Version       int       `json:"version"`
+	ParentVersion int       `json:"parentVersion"`
+	RestoredFrom  int       `json:"restoredFrom"`
+	Created       time.Time `json:"created"`
+	CreatedBy     string    `json:"createdBy"`
+	Message       string    `json:"message"`
+}
+
+// DashboardVersionFull é uma versão com o JSON do dashboard naquela versão
+type DashboardVersionFull struct {
+	DashboardVersion
+	Data map[string]any `json:"data"`
+}
+
+// ListDashboardVersions lista o histórico (mais novo primeiro); limit <= 0 = default do Grafana.
+// Grafana 11 devolve {"versions":[...],"continueToken"}; versões antigas devolvem a lista direto.
+func (c *Client) ListDashboardVersions(ctx context.Context, uid string, limit int) ([]DashboardVersion, error) {
+	path := "/api/dashboards/uid/" + url.PathEscape(uid) + "/versions"
+	if limit > 0 {
+		path += "?limit=" + strconv.Itoa(limit)
+	}
+
+	var raw json.RawMessage
+	if err := c.do(ctx, "GET", path, nil, &raw); err != nil {
+		return nil, err
+	}
+
+	var out []DashboardVersion
+	if len(raw) > 0 && raw[0] == '[' {
+		if err := json.Unmarshal(raw, &out); err != nil {
+			return nil, fmt.Errorf("decode versions: %w", err)
+		}
+		return out, nil
+	}
+
+	var page struct {
+		Versions []DashboardVersion `json:"versions"`
+	}
+	if err := json.Unmarshal(raw, &page); err != nil {
+		return nil, fmt.Errorf("decode versions: %w", err)
+	}
+	return page.Versions, nil
+}
+
+// GetDashboardVersion lê o dashboard como estava numa versão do histórico
+func (c *Client) GetDashboardVersion(ctx context.Context, uid string, version int) (*DashboardVersionFull, error) {
+	var out DashboardVersionFull
+	path := fmt.Sprintf("/api/dashboards/uid/%s/versions/%d", url.PathEscape(uid), version)
+	if err := c.do(ctx, "GET", path, nil, &out); err != nil {
+		return nil, err
+	}
+	if out.Data == nil {
+		return nil, fmt.Errorf("dashboard %s version %d: empty data", uid, version)
+	}
+	return &out, nil
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/grafana"
+	"dashboard-transporter/internal/transport"
+)
+
+// ExportBundle gera um ZIP com os dashboards de um ambiente (ou de um folder e subfolders):
+// GET /api/v1/export/bundle?env=dev&folderUid=<uid>
+//
+// Layout:
+//
+//	dashboards/<caminho do folder>/<titulo>-<uid>.json
+//	library-panels/<uid>.json
+//	manifest.json (env de origem, versões, checksums, datasources, library panels)
+func ExportBundle(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		envID := r.URL.Query().Get("env")
+		folderUID := r.URL.Query().Get("folderUid")
+
+		if envID == "" {
+			writeError(w, http.StatusBadRequest, "missing env")
+			return
+		}
+
+		env := cfg.GetEnvironment(envID)
+		if env == nil {
+			writeError(w, http.StatusBadRequest, "unknown env: "+envID)
+			return
+		}
+
+		client := grafana.ClientForEnvironment(env).WithOrg(getOrgIDFromRequest(r))
+
+		dashboards, folderPath, err := dashboardsInFolder(ctx, client, folderUID)
+		if err != nil {
+			writeError(w, folderErrorStatus(err), err.Error())
+			return
+		}
+
+		grafanaVersion := ""
+		if h, err := client.Health(ctx); err == nil {
+			grafanaVersion = h.Version
+		}
+
+		slog.InfoContext(ctx, "exporting bundle", "env", env.ID, "folder", folderUID, "user", grafanaLoggedUserFromHeaders(r))
+
+		// a partir daqui é streaming: erro de item vai p/ manifest.errors
+		name := env.ID
+		if folderUID != "" {
+			name += "-" + folderUID
+		}
+		w.Header().Set("Content-Type", "application/zip")
+		setAttachment(w, fmt.Sprintf("%s-%s.zip", name, time.Now().UTC().Format("20060102-150405")))
+
+		bundle := transport.NewBundleWriter(w, transport.BundleManifest{
+			SourceEnv:      env.ID,
+			SourceURL:      env.URL,
+			GrafanaVersion: grafanaVersion,
+			FolderUID:      folderUID,
+			CreatedBy:      grafanaLoggedUserFromHeaders(r),
+		})
+
+		libraryPanels := map[string]bool{}
+		var libraryOrder []string
+
+		for _, d := range dashboards {
+			full, err := client.GetDashboardFull(ctx, d.UID)
+			if err != nil {
+				slog.WarnContext(ctx, "bundle: dashboard skipped", "env", env.ID, "uid", d.UID, "error", err)
+				bundle.AddError("dashboard", d.UID, err.Error())
+				continue
+			}
+
+			fUID := full.FolderUID()
+			if err := bundle.AddDashboard(full.Dashboard, fUID, folderPath[fUID]); err != nil {
+				// erro de escrita no ZIP = cliente desconectou; não tem o que salvar
+				slog.ErrorContext(ctx, "bundle write failed", "env", env.ID, "error", err)
+				return
+			}
+
+			for _, lp := range transport.LibraryPanelRefs(full.Dashboard) {
+				if !libraryPanels[lp.UID] {
+					libraryPanels[lp.UID] = true
+					libraryOrder = append(libraryOrder, lp.UID)
+				}
+			}
+		}
+
+		for _, uid := range libraryOrder {
+			el, err := client.GetLibraryElement(ctx, uid)
+			if err != nil {
+				bundle.AddError("libraryPanel", uid, err.Error())
+				continue
+			}
+			if err := bundle.AddLibraryPanel(el.UID, el.Name, el.FolderUID, el.Version, el); err != nil {
+				slog.ErrorContext(ctx, "bundle write failed", "env", env.ID, "error", err)
+				return
+			}
+		}
+
+		if err := bundle.Close(); err != nil {
+			slog.ErrorContext(ctx, "bundle write failed", "env", env.ID, "error", err)
+		}
+	}
+}
+
+// errFolderNotFound é devolvido por dashboardsInFolder quando o folderUid não existe
+var errFolderNotFound = errors.New("folder not found")
+
+func folderErrorStatus(err error) int {
+	if errors.Is(err, errFolderNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// dashboardsInFolder lista os dashboards do folder e de todos os subfolders
+// (folderUID vazio = ambiente inteiro) e devolve também o caminho de cada folder.
+func dashboardsInFolder(ctx context.Context, client *grafana.Client, folderUID string) ([]grafana.DashboardSearchItem, map[string]string, error) {
+	folders, err := client.ListFoldersFlat(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list folders: %w", err)
+	}
+	folderPath := make(map[string]string, len(folders))
+	for _, f := range folders {
+		folderPath[f.UID] = f.Title
+	}
+
+	var selected map[string]bool
+	if folderUID != "" {
+		if _, ok := folderPath[folderUID]; !ok {
+			return nil, nil, fmt.Errorf("%w: %s", errFolderNotFound, folderUID)
+		}
+		selected = grafana.DescendantFolderUIDs(folders, folderUID)
+	}
+
+	all, err := client.ListDashboards(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list dashboards: %w", err)
+	}
+
+	out := make([]grafana.DashboardSearchItem, 0, len(all))
+	for _, d := range all {
+		if selected != nil && !selected[d.FolderUID] {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out, folderPath, nil
+}
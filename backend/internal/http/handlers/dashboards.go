@@ -1,78 +1,128 @@
 package handlers
 
 import (
-	"encoding/json"
-	"fmt"
 	"net/http"
-	"net/url"
+	"strconv"
+	"strings"
 
+	"dashboard-transporter/internal/cache"
 	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/grafana"
 )
 
-type dashboardOut struct {
-	ID    int    `json:"id"`
-	UID   string `json:"uid"`
-	Title string `json:"title"`
-}
-
-type grafanaSearchItem struct {
-	ID    int    `json:"id"`
-	UID   string `json:"uid"`
-	Title string `json:"title"`
-	Type  string `json:"type"`
-}
+// limite máximo de itens por página em /dashboards
+const maxDashboardsLimit = 5000
 
-func Dashboards(cfg *config.Config) http.HandlerFunc {
+// Dashboards lista/filtra os dashboards do ambiente:
+// GET /api/v1/dashboards?env=dev&query=cpu&tag=infra&folderUid=abc&starred=true&page=1&limit=100
+//
+// tag e folderUid aceitam repetição ou lista separada por vírgula. Sem page/limit
+// devolve tudo (compatível com o plugin); o total vem sempre em X-Total-Count.
+func Dashboards(cfg *config.Config, catalog *cache.Catalog) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		envID := r.URL.Query().Get("env")
+		q := r.URL.Query()
+
+		envID := q.Get("env")
 		if envID == "" {
-			http.Error(w, "missing env", http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, "missing env")
 			return
 		}
 
 		env := cfg.GetEnvironment(envID)
 		if env == nil {
-			http.Error(w, "unknown env: "+envID, http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, "unknown env: "+envID)
 			return
 		}
 
-		base := stringsTrimRightSlash(env.URL)
-		endpoint := base + "/api/search?type=dash-db"
+		page, err := queryInt(q.Get("page"), 1)
+		if err != nil || page < 1 {
+			writeError(w, http.StatusBadRequest, "invalid page")
+			return
+		}
+		limit, err := queryInt(q.Get("limit"), 0)
+		if err != nil || limit < 0 || limit > maxDashboardsLimit {
+			writeError(w, http.StatusBadRequest, "invalid limit (0-5000)")
+			return
+		}
 
-		req, _ := http.NewRequest(http.MethodGet, endpoint, nil)
-		req.SetBasicAuth(env.User, env.Password)
+		search := grafana.SearchQuery{
+			Query:      strings.TrimSpace(q.Get("query")),
+			Tags:       queryList(q["tag"]),
+			FolderUIDs: queryList(q["folderUid"]),
+			Starred:    q.Get("starred") == "true",
+		}
 
-		resp, err := http.DefaultClient.Do(req)
+		items, err := catalog.Dashboards(r.Context(), env, search)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, grafanaErrorStatus(err), "search dashboards: "+err.Error())
 			return
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode >= 300 {
-			http.Error(w, fmt.Sprintf("grafana api error (%d) on search", resp.StatusCode), http.StatusInternalServerError)
+		folders, err := catalog.Folders(r.Context(), env)
+		if err != nil {
+			writeError(w, grafanaErrorStatus(err), "list folders: "+err.Error())
 			return
 		}
+		folderPath := make(map[string]string, len(folders))
+		for _, f := range folders {
+			folderPath[f.UID] = f.Title
+		}
 
-		var items []grafanaSearchItem
-		if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
-			http.Error(w, "failed to decode grafana response: "+err.Error(), http.StatusInternalServerError)
-			return
+		// items é compartilhado com o cache: só fatiar, nunca alterar
+		w.Header().Set("X-Total-Count", strconv.Itoa(len(items)))
+		if limit > 0 {
+			// page enorme: compara antes de multiplicar (page-1)*limit estoura o int
+			start := len(items)
+			if page-1 <= len(items)/limit {
+				start = min((page-1)*limit, len(items))
+			}
+			items = items[start:min(start+limit, len(items))]
 		}
 
+		base := stringsTrimRightSlash(env.URL)
 		out := make([]dashboardOut, 0, len(items))
 		for _, it := range items {
-			if it.Type != "" && it.Type != "dash-db" {
-				continue
+			path, ok := folderPath[it.FolderUID]
+			if !ok {
+				path = it.FolderTitle
+			}
+			tags := it.Tags
+			if tags == nil {
+				tags = []string{}
+			}
+			d := dashboardOut{
+				ID:         it.ID,
+				UID:        it.UID,
+				Title:      it.Title,
+				FolderUID:  it.FolderUID,
+				FolderPath: path,
+				Tags:       tags,
+			}
+			if it.URL != "" {
+				d.URL = base + it.URL
 			}
-			out = append(out, dashboardOut{ID: it.ID, UID: it.UID, Title: it.Title})
+			out = append(out, d)
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(out)
+		writeJSONCached(w, r, out)
+	}
+}
+
+// queryInt lê um inteiro da query string; vazio => def
+func queryInt(v string, def int) (int, error) {
+	if v == "" {
+		return def, nil
+	}
+	return strconv.Atoi(v)
+}
 
-		_ = url.QueryEscape("") // mantém import url usado nesse arquivo sem warnings em alguns linters
+// queryList junta valores repetidos e separados por vírgula, sem vazios
+func queryList(values []string) []string {
+	var out []string
+	for _, v := range values {
+		out = append(out, splitList(v)...)
 	}
+	return out
 }
 
 // util local (sem depender de outros arquivos)
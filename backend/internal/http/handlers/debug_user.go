@@ -9,60 +9,53 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/grafana"
 )
 
-type grafanaUserLookup struct {
-	ID    int    `json:"id"`
-	Email string `json:"email"`
-	Login string `json:"login"`
-	Name  string `json:"name"`
-}
-
 func DebugUser(cfg *config.Config) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		envID := chi.URLParam(r, "env")
 		username := chi.URLParam(r, "username")
 
 		if envID == "" || username == "" {
-			http.Error(w, "missing env or username", http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, "missing env or username")
 			return
 		}
 
 		env := cfg.GetEnvironment(envID)
 		if env == nil {
-			http.Error(w, "unknown env: "+envID, http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, "unknown env: "+envID)
 			return
 		}
 
 		base := stringsTrimRightSlash(env.URL)
 		endpoint := base + "/api/users/lookup?loginOrEmail=" + url.QueryEscape(username)
 
-		req, _ := http.NewRequest(http.MethodGet, endpoint, nil)
+		req, _ := http.NewRequestWithContext(r.Context(), http.MethodGet, endpoint, nil)
 		req.SetBasicAuth(env.User, env.Password)
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := grafana.HTTPClient(env.ID).Do(req)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 		defer resp.Body.Close()
 
 		if resp.StatusCode == 404 {
-			http.Error(w, "user not found", http.StatusNotFound)
+			writeError(w, http.StatusNotFound, "user not found")
 			return
 		}
 		if resp.StatusCode >= 300 {
-			http.Error(w, fmt.Sprintf("grafana api error (%d) on user lookup", resp.StatusCode), http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("grafana api error (%d) on user lookup", resp.StatusCode))
 			return
 		}
 
-		var u grafanaUserLookup
+		var u userOut
 		if err := json.NewDecoder(resp.Body).Decode(&u); err != nil {
-			http.Error(w, "failed to decode grafana response: "+err.Error(), http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, "failed to decode grafana response: "+err.Error())
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(u)
+		writeJSON(w, http.StatusOK, u)
 	}
 }
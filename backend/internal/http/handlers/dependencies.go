@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/grafana"
+	"dashboard-transporter/internal/transport"
+)
+
+// DashboardDependencies monta o grafo de links (/d/<uid>) a partir dos dashboards
+// selecionados e diz quais estão faltando ou desatualizados no destino:
+// GET /api/v1/dashboards/dependencies?sourceEnv=dev&targetEnv=hml&uid=a&uid=b
+func DashboardDependencies(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		q := r.URL.Query()
+
+		src := cfg.GetEnvironment(q.Get("sourceEnv"))
+		dst := cfg.GetEnvironment(q.Get("targetEnv"))
+		if src == nil || dst == nil {
+			writeError(w, http.StatusBadRequest, "unknown sourceEnv or targetEnv")
+			return
+		}
+		uids := queryUIDs(r)
+		if len(uids) == 0 {
+			writeError(w, http.StatusBadRequest, "uid is required")
+			return
+		}
+
+		orgID := getOrgIDFromRequest(r)
+		graph := transport.BuildDependencyGraph(ctx, sourceFetcher(src, orgID), uids)
+		target := grafana.ClientForEnvironment(dst).WithOrg(orgID)
+
+		out := dependencyGraphOut{
+			SourceEnv: src.ID,
+			TargetEnv: dst.ID,
+			Roots:     graph.Roots,
+			Truncated: graph.Truncated,
+			Nodes:     make([]dependencyNodeOut, 0, len(graph.Nodes)),
+			Edges:     []dependencyEdge{},
+			Closure:   graph.Closure(),
+			Missing:   []string{},
+			Outdated:  []string{},
+		}
+
+		for _, n := range graph.Nodes {
+			node := dependencyNodeOut{DependencyNode: n}
+			for _, to := range n.Links {
+				out.Edges = append(out.Edges, dependencyEdge{From: n.UID, To: to})
+			}
+			if n.Found {
+				node.Target = targetState(ctx, target, n)
+				switch node.Target.Status {
+				case "missing":
+					out.Missing = append(out.Missing, n.UID)
+				case "outdated":
+					out.Outdated = append(out.Outdated, n.UID)
+				}
+			}
+			out.Nodes = append(out.Nodes, node)
+		}
+
+		writeJSON(w, http.StatusOK, out)
+	}
+}
+
+// sourceFetcher lê os dashboards da origem p/ o grafo (na org do request)
+func sourceFetcher(src *config.Environment, orgID string) transport.DashboardFetcher {
+	client := grafana.ClientForEnvironment(src).WithOrg(orgID)
+	return func(ctx context.Context, uid string) (map[string]any, bool, error) {
+		full, err := client.GetDashboardFull(ctx, uid)
+		if grafana.IsNotFound(err) {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return full.Dashboard, true, nil
+	}
+}
+
+// targetState compara o dashboard da origem com o do destino pelo hash do conteúdo
+func targetState(ctx context.Context, target *grafana.Client, n *transport.DependencyNode) *dependencyTarget {
+	full, err := target.GetDashboardFull(ctx, n.UID)
+	if grafana.IsNotFound(err) {
+		return &dependencyTarget{Status: "missing"}
+	}
+	if err != nil {
+		return &dependencyTarget{Status: "unknown", Error: err.Error()}
+	}
+
+	st := &dependencyTarget{Status: "in_sync"}
+	if v, ok := full.Dashboard["version"].(float64); ok {
+		st.Version = int(v)
+	}
+	if hash, _ := transport.ContentHash(full.Dashboard); hash != n.Hash {
+		st.Status = "outdated"
+	}
+	return st
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/drift"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Drift compara os dashboards (por UID) entre ambientes:
+// GET /api/v1/drift?envs=dev,hml,prd (vazio = todos os configurados, na ordem da config)
+// &folders=Time A,Time B/Projeto X (opcional; paths com subfolders)
+func Drift(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		envs, err := drift.ResolveEnvironments(cfg, splitList(r.URL.Query().Get("envs")))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, drift.Compute(r.Context(), envs, getOrgIDFromRequest(r), splitList(r.URL.Query().Get("folders"))))
+	}
+}
+
+// splitList quebra "a, b,,c" em [a b c]
+func splitList(raw string) []string {
+	var out []string
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+const driftSchedulerDisabledMsg = "drift scheduler disabled (check DRIFT_* config)"
+
+// DriftRuns lista as execuções guardadas (mais recente primeiro, sem o relatório completo)
+func DriftRuns(sched *drift.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if sched == nil {
+			writeError(w, http.StatusNotImplemented, driftSchedulerDisabledMsg)
+			return
+		}
+		runs := sched.Store().List()
+		out := make([]drift.Run, 0, len(runs))
+		for _, run := range runs {
+			item := *run
+			item.Report = nil
+			out = append(out, item)
+		}
+		writeJSON(w, http.StatusOK, out)
+	}
+}
+
+// DriftRun devolve uma execução com o relatório ({id} = "latest" p/ a mais recente)
+func DriftRun(sched *drift.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if sched == nil {
+			writeError(w, http.StatusNotImplemented, driftSchedulerDisabledMsg)
+			return
+		}
+		run := sched.Store().Get(chi.URLParam(r, "id"))
+		if run == nil {
+			writeError(w, http.StatusNotFound, "drift run not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, run)
+	}
+}
+
+// TriggerDriftRun executa agora (mesmo fluxo do agendamento, inclusive notificação)
+func TriggerDriftRun(sched *drift.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if sched == nil {
+			writeError(w, http.StatusNotImplemented, driftSchedulerDisabledMsg)
+			return
+		}
+		run, err := sched.Run(r.Context(), "manual")
+		if errors.Is(err, drift.ErrRunning) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, run)
+	}
+}
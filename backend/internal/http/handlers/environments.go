@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"dashboard-transporter/internal/config"
@@ -9,7 +8,6 @@ import (
 
 func Environments(cfg *config.Config) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(cfg.Environments)
+		writeJSON(w, http.StatusOK, cfg.Environments)
 	}
 }
@@ -1,43 +1,154 @@
 package handlers
 
 import (
-	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
 
 	"dashboard-transporter/internal/config"
 	"dashboard-transporter/internal/grafana"
+	"dashboard-transporter/internal/transport"
 )
 
+// queryUIDs aceita ?uid=a&uid=b e/ou ?uids=a,b (sem duplicados, mantendo a ordem)
+func queryUIDs(r *http.Request) []string {
+	q := r.URL.Query()
+	raw := append([]string{}, q["uid"]...)
+	for _, v := range q["uids"] {
+		raw = append(raw, strings.Split(v, ",")...)
+	}
+
+	out := make([]string, 0, len(raw))
+	seen := map[string]struct{}{}
+	for _, uid := range raw {
+		uid = strings.TrimSpace(uid)
+		if uid == "" {
+			continue
+		}
+		if _, ok := seen[uid]; ok {
+			continue
+		}
+		seen[uid] = struct{}{}
+		out = append(out, uid)
+	}
+	return out
+}
+
+// grafanaErrorStatus mapeia erro do Grafana p/ status do backend (404 passa, resto 500)
+func grafanaErrorStatus(err error) int {
+	var apiErr *grafana.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// ExportDashboard exporta um ou vários dashboards de um ambiente:
+// GET /api/v1/dashboards/export?env=dev&uid=a&uid=b&format=raw|external|operator|terraform
 func ExportDashboard(cfg *config.Config) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
 		envID := r.URL.Query().Get("env")
-		uid := r.URL.Query().Get("uid")
+		uids := queryUIDs(r)
+
+		if envID == "" || len(uids) == 0 {
+			writeError(w, http.StatusBadRequest, "env and uid are required")
+			return
+		}
 
-		if envID == "" || uid == "" {
-			http.Error(w, "env and uid are required", http.StatusBadRequest)
+		format, err := transport.ParseExportFormat(r.URL.Query().Get("format"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
 			return
 		}
 
 		env, ok := cfg.FindEnvironment(envID)
 		if !ok {
-			http.Error(w, "environment not found", http.StatusNotFound)
+			writeError(w, http.StatusNotFound, "environment not found")
 			return
 		}
 
-		log.Printf("[HANDLER] Export - Environment: %s, UID: %s", env.ID, uid)
+		slog.InfoContext(ctx, "exporting dashboards", "env", env.ID, "uids", len(uids), "format", format)
 
 		// ✅ Usar credenciais do config
-		client := grafana.NewClient(env.URL, env.User, env.Password)
+		client := grafana.ClientForEnvironment(env).WithOrg(getOrgIDFromRequest(r))
 
-		dashboard, err := client.GetDashboardByUID(uid)
-		if err != nil {
-			log.Printf("[HANDLER] Error getting dashboard: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+		items := make([]transport.ExportItem, 0, len(uids))
+		for _, uid := range uids {
+			full, err := client.GetDashboardFull(ctx, uid)
+			if err != nil {
+				slog.ErrorContext(ctx, "export failed", "env", env.ID, "uid", uid, "error", err)
+				writeError(w, grafanaErrorStatus(err), fmt.Sprintf("dashboard %s: %s", uid, err.Error()))
+				return
+			}
+			items = append(items, transport.ExportItem{
+				Dashboard:   full.Dashboard,
+				FolderUID:   full.FolderUID(),
+				FolderTitle: full.FolderTitle(),
+			})
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(dashboard)
+		filename := uids[0]
+		if len(uids) > 1 {
+			filename = env.ID + "-dashboards"
+		}
+
+		switch format {
+		case transport.FormatRaw, transport.FormatExternal:
+			dashboards := make([]any, 0, len(items))
+
+			if format == transport.FormatExternal {
+				datasources, err := client.ListDataSources(ctx)
+				if err != nil {
+					writeError(w, http.StatusInternalServerError, "list datasources: "+err.Error())
+					return
+				}
+				version := ""
+				if h, err := client.Health(ctx); err == nil {
+					version = h.Version
+				}
+				for _, it := range items {
+					dashboards = append(dashboards, transport.ExternalExport(it.Dashboard, datasources, version))
+				}
+			} else {
+				for _, it := range items {
+					dashboards = append(dashboards, it.Dashboard)
+				}
+			}
+
+			// sempre array (mesmo com 1 uid): o cliente não precisa adivinhar o formato
+			setAttachment(w, filename+".json")
+			writeJSON(w, http.StatusOK, dashboards)
+
+		case transport.FormatOperator:
+			body, err := transport.RenderOperator(items, transport.OperatorOptions{
+				Namespace:     r.URL.Query().Get("namespace"),
+				InstanceLabel: r.URL.Query().Get("instanceSelector"),
+				SourceEnv:     env.ID,
+			})
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			w.Header().Set("Content-Type", "application/yaml")
+			setAttachment(w, filename+".yaml")
+			_, _ = w.Write(body)
+
+		case transport.FormatTerraform:
+			body, err := transport.RenderTerraform(items)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			setAttachment(w, filename+".tf")
+			_, _ = w.Write(body)
+		}
 	}
-}
\ No newline at end of file
+}
+
+func setAttachment(w http.ResponseWriter, filename string) {
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+}
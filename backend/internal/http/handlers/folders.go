@@ -1,35 +1,29 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 
+	"dashboard-transporter/internal/cache"
 	"dashboard-transporter/internal/config"
-	"dashboard-transporter/internal/grafana"
 )
 
-type folderOut struct {
-	UID   string `json:"uid"`
-	Title string `json:"title"`
-}
-
-func Folders(cfg *config.Config) http.HandlerFunc {
+func Folders(cfg *config.Config, catalog *cache.Catalog) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		envID := r.URL.Query().Get("env")
 		if envID == "" {
-			http.Error(w, "missing env", http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, "missing env")
 			return
 		}
 
-		client, err := grafana.NewClientFromEnv(cfg, envID)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		env := cfg.GetEnvironment(envID)
+		if env == nil {
+			writeError(w, http.StatusBadRequest, "unknown env: "+envID)
 			return
 		}
 
-		folders, err := client.ListFoldersFlat() // <- precisa existir no folders.go do grafana
+		folders, err := catalog.Folders(r.Context(), env)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 
@@ -41,7 +35,6 @@ func Folders(cfg *config.Config) http.HandlerFunc {
 			})
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(out)
+		writeJSONCached(w, r, out)
 	}
 }
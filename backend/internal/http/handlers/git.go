@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/gitrepo"
+	"dashboard-transporter/internal/grafana"
+	"dashboard-transporter/internal/transport"
+)
+
+// sourceGit é o "ambiente de origem" de imports feitos a partir do repo Git (métricas/logs)
+const sourceGit = "git"
+
+const gitDisabledMsg = "git integration disabled (set GIT_REPO_PATH)"
+
+// GitExport commita dashboards de um ambiente no repo Git:
+// POST /api/v1/git/export {"env":"dev","uids":[...]} ou {"env":"dev","folderUid":"..."}
+func GitExport(cfg *config.Config, repo *gitrepo.Repo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if repo == nil {
+			writeError(w, http.StatusNotImplemented, gitDisabledMsg)
+			return
+		}
+		ctx := r.Context()
+
+		var req gitExportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json body")
+			return
+		}
+		env := cfg.GetEnvironment(req.Env)
+		if env == nil {
+			writeError(w, http.StatusBadRequest, "unknown env: "+req.Env)
+			return
+		}
+
+		client := grafana.ClientForEnvironment(env).WithOrg(getOrgIDFromRequest(r))
+
+		folders, err := client.ListFoldersFlat(ctx)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "list folders: "+err.Error())
+			return
+		}
+		folderPath := make(map[string]string, len(folders))
+		for _, f := range folders {
+			folderPath[f.UID] = f.Title
+		}
+
+		uids := req.UIDs
+		if len(uids) == 0 {
+			items, _, err := dashboardsInFolder(ctx, client, req.FolderUID)
+			if err != nil {
+				writeError(w, folderErrorStatus(err), err.Error())
+				return
+			}
+			for _, it := range items {
+				uids = append(uids, it.UID)
+			}
+		}
+		if len(uids) == 0 {
+			writeError(w, http.StatusBadRequest, "no dashboards to export")
+			return
+		}
+
+		files := make([]gitrepo.File, 0, len(uids))
+		var summary []string
+		for _, uid := range uids {
+			full, err := client.GetDashboardFull(ctx, uid)
+			if err != nil {
+				writeError(w, grafanaErrorStatus(err), fmt.Sprintf("dashboard %s: %s", uid, err.Error()))
+				return
+			}
+			content, err := transport.ProvisioningJSON(full.Dashboard)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			files = append(files, gitrepo.File{
+				UID:        uid,
+				FolderPath: folderPath[full.FolderUID()],
+				Content:    content,
+			})
+
+			title, _ := full.Dashboard["title"].(string)
+			summary = append(summary, fmt.Sprintf("- %s %q (version %v)", uid, title, full.Dashboard["version"]))
+		}
+
+		requester := grafanaLoggedUserFromHeaders(r)
+		message := gitCommitMessage(req.Message, env, requester, summary)
+
+		res, err := repo.CommitDashboards(ctx, req.Branch, message, files)
+		out := gitExportOut{Branch: req.Branch, Files: []string{}, Dashboards: len(files)}
+		if out.Branch == "" {
+			out.Branch = repo.DefaultBranch()
+		}
+
+		switch {
+		case errors.Is(err, gitrepo.ErrNothingToCommit):
+			out.Unchanged = true
+		case err != nil && res == nil:
+			slog.ErrorContext(ctx, "git export failed", "env", env.ID, "user", requester, "error", err)
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		default:
+			out.Commit, out.Branch, out.Files, out.Pushed = res.Commit, res.Branch, res.Files, res.Pushed
+			if err != nil {
+				// commit local ok, push falhou
+				slog.WarnContext(ctx, "git export committed locally only", "env", env.ID, "commit", res.Commit, "error", err)
+			}
+		}
+
+		slog.InfoContext(ctx, "git export finished", "env", env.ID, "user", requester, "branch", out.Branch, "commit", out.Commit, "dashboards", len(files), "unchanged", out.Unchanged)
+		writeJSON(w, http.StatusOK, out)
+	}
+}
+
+// GitRefs lista branches e tags do repo (p/ escolher a origem de um import)
+func GitRefs(repo *gitrepo.Repo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if repo == nil {
+			writeError(w, http.StatusNotImplemented, gitDisabledMsg)
+			return
+		}
+		refs, err := repo.Refs(r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if refs == nil {
+			refs = []gitrepo.Ref{}
+		}
+		writeJSON(w, http.StatusOK, refs)
+	}
+}
+
+func gitCommitMessage(subject string, env *config.Environment, requester string, summary []string) string {
+	subject = strings.TrimSpace(strings.SplitN(subject, "\n", 2)[0])
+	if subject == "" {
+		subject = fmt.Sprintf("Export %d dashboard(s) from %s", len(summary), env.ID)
+	}
+	if requester == "" {
+		requester = "unknown"
+	}
+
+	var b strings.Builder
+	b.WriteString(subject + "\n\n")
+	b.WriteString(strings.Join(summary, "\n") + "\n\n")
+	b.WriteString("Source-Env: " + env.ID + "\n")
+	b.WriteString("Source-URL: " + env.URL + "\n")
+	b.WriteString("Requested-By: " + requester + "\n")
+	return b.String()
+}
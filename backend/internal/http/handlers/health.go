@@ -1,10 +1,107 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"sync"
+	"time"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/grafana"
+	"dashboard-transporter/internal/transport"
 )
 
+// tempo máximo para o probe de cada ambiente no /readyz
+const readinessProbeTimeout = 5 * time.Second
+
+// Health é o liveness: só diz que o processo está de pé (não toca no Grafana)
 func Health(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(`{"status":"ok"}`))
 }
+
+// Ready é o readiness: faz probe em cada ambiente configurado
+// (/api/health + chamada autenticada em /api/user) e devolve 503
+// se algum ambiente marcado como required estiver fora.
+func Ready(cfg *config.Config, jobs *transport.Jobs) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// desligando: tira o backend do balanceador antes de tudo
+		if jobs.Draining() {
+			writeJSON(w, http.StatusServiceUnavailable, readinessOut{Status: "shutting_down", Environments: []envReadiness{}})
+			return
+		}
+
+		results := make([]envReadiness, len(cfg.Environments))
+
+		var wg sync.WaitGroup
+		for i := range cfg.Environments {
+			wg.Add(1)
+			go func(i int, env config.Environment) {
+				defer wg.Done()
+				results[i] = probeEnvironment(r.Context(), env)
+			}(i, cfg.Environments[i])
+		}
+		wg.Wait()
+
+		out := readinessOut{Status: "ok", Environments: results}
+		code := http.StatusOK
+
+		for _, res := range results {
+			if res.Status == "ok" {
+				continue
+			}
+			if res.Required {
+				out.Status = "down"
+				code = http.StatusServiceUnavailable
+				break
+			}
+			out.Status = "degraded"
+		}
+
+		if len(results) == 0 {
+			out.Status = "down"
+			code = http.StatusServiceUnavailable
+		}
+
+		writeJSON(w, code, out)
+	}
+}
+
+func probeEnvironment(parent context.Context, env config.Environment) envReadiness {
+	res := envReadiness{
+		ID:       env.ID,
+		Name:     env.Name,
+		Required: env.Required,
+		Status:   "down",
+	}
+
+	ctx, cancel := context.WithTimeout(parent, readinessProbeTimeout)
+	defer cancel()
+
+	client := grafana.ClientForEnvironment(&env)
+
+	start := time.Now()
+	health, err := client.Health(ctx)
+	res.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		res.Error = "health: " + err.Error()
+		return res
+	}
+	res.Version = health.Version
+	res.Database = health.Database
+
+	if health.Database != "" && health.Database != "ok" {
+		res.Error = "database: " + health.Database
+		return res
+	}
+
+	if _, err := client.CurrentUser(ctx); err != nil {
+		res.Error = "auth: " + err.Error()
+		return res
+	}
+	res.Authenticated = true
+	res.Status = "ok"
+
+	return res
+}
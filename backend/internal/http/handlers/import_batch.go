@@ -2,31 +2,27 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
+	"time"
 
+	"dashboard-transporter/internal/cache"
 	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/gitrepo"
+	"dashboard-transporter/internal/grafana"
+	"dashboard-transporter/internal/logging"
+	"dashboard-transporter/internal/metrics"
+	"dashboard-transporter/internal/transport"
+	"dashboard-transporter/internal/webhooks"
 )
 
-type importBatchRequest struct {
-	SourceEnv   string   `json:"sourceEnv"`
-	TargetEnv   string   `json:"targetEnv"`
-	FolderUID   string   `json:"folderUid"`
-	RequestedBy string   `json:"requestedBy"` // pode ser lista: "a,b;c\n d"
-	UIDs        []string `json:"uids"`
-}
-
-type importBatchResult struct {
-	SourceUID string `json:"sourceUid"`
-	TargetUID string `json:"targetUid,omitempty"`
-	Status    string `json:"status"`            // ok | warning | error
-	Message   string `json:"message,omitempty"` // detalhes
-}
-
 type grafanaDashboardGetResp struct {
 	Meta struct {
 		ID  int    `json:"id"`
@@ -39,6 +35,7 @@ type grafanaImportReq struct {
 	Dashboard map[string]any `json:"dashboard"`
 	FolderUID string         `json:"folderUid"`
 	Overwrite bool           `json:"overwrite"`
+	Message   string         `json:"message,omitempty"` // aparece no histórico de versões do destino
 }
 
 type grafanaImportResp struct {
@@ -49,26 +46,6 @@ type grafanaImportResp struct {
 	Version int    `json:"version"`
 }
 
-type grafanaUserLookupResp struct {
-	ID    int    `json:"id"`
-	Email string `json:"email"`
-	Login string `json:"login"`
-	Name  string `json:"name"`
-}
-
-type grafanaDashPermGetResp struct {
-	DashboardID int `json:"dashboardId"`
-	Permissions []struct {
-		ID         int    `json:"id"`
-		UserID     int    `json:"userId"`
-		UserLogin  string `json:"userLogin"`
-		UserEmail  string `json:"userEmail"`
-		TeamID     int    `json:"teamId"`
-		Role       string `json:"role"`
-		Permission int    `json:"permission"`
-	} `json:"permissions"`
-}
-
 func getOrgIDFromRequest(r *http.Request) string {
 	v := strings.TrimSpace(r.Header.Get("X-Grafana-Org-Id"))
 	if v == "" {
@@ -108,48 +85,122 @@ func parseRequestedByList(in string) []string {
 	return out
 }
 
-func ImportDashboardsBatch(cfg *config.Config) http.HandlerFunc {
+func ImportDashboardsBatch(cfg *config.Config, pipeline ImportPipeline, jobs *transport.Jobs, repo *gitrepo.Repo, hooks *webhooks.Dispatcher, catalog *cache.Catalog) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		orgID := getOrgIDFromRequest(r)
 
 		var req importBatchRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "invalid json body", http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, "invalid json body")
 			return
 		}
-		if req.SourceEnv == "" || req.TargetEnv == "" {
-			http.Error(w, "sourceEnv and targetEnv are required", http.StatusBadRequest)
+		if (req.SourceEnv == "" && req.SourceRef == "") || req.TargetEnv == "" {
+			writeError(w, http.StatusBadRequest, "sourceEnv (or sourceRef) and targetEnv are required")
 			return
 		}
 		if len(req.UIDs) == 0 {
-			http.Error(w, "uids is required", http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, "uids is required")
+			return
+		}
+		if err := validateVersions(req); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := validateGrants(req.Grants, newGrantPolicy(cfg, req.TargetEnv)); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// origem = commit/tag/branch do repo Git
+		if req.SourceRef != "" {
+			if req.IncludeLinked {
+				writeError(w, http.StatusBadRequest, "includeLinked requires sourceEnv")
+				return
+			}
+			if req.CopyPermissions {
+				writeError(w, http.StatusBadRequest, "copyPermissions requires sourceEnv")
+				return
+			}
+			importBatchFromGit(w, r, cfg, pipeline, jobs, repo, hooks, catalog, req, orgID)
 			return
 		}
 
 		src := cfg.GetEnvironment(req.SourceEnv)
 		dst := cfg.GetEnvironment(req.TargetEnv)
 		if src == nil || dst == nil {
-			http.Error(w, "unknown sourceEnv or targetEnv", http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, "unknown sourceEnv or targetEnv")
 			return
 		}
 
+		// fecho dos links (/d/<uid>): linkados entram no mesmo batch, depois dos selecionados
+		if req.IncludeLinked {
+			graph := transport.BuildDependencyGraph(r.Context(), sourceFetcher(src, orgID), req.UIDs)
+			req.UIDs = mergeUIDs(req.UIDs, graph.Closure())
+		}
+
 		// ✅ usa a função que já existe no package (definida em dashboards.go)
 		srcBase := stringsTrimRightSlash(src.URL)
-		dstBase := stringsTrimRightSlash(dst.URL)
 
+		// clients instrumentados (métricas de latência por ambiente)
+		srcHTTP := grafana.HTTPClient(src.ID)
+		srcClient := grafana.ClientForEnvironment(src).WithOrg(orgID)
+
+		ctx, done, ok := startTransportJob(w, r, jobs)
+		if !ok {
+			return
+		}
+		defer done()
+
+		requestedBy := grafanaLoggedUserFromHeaders(r)
+		slog.InfoContext(ctx, "transport started",
+			"source_env", src.ID,
+			"target_env", dst.ID,
+			"folder", req.FolderUID,
+			"uids", len(req.UIDs),
+			"include_linked", req.IncludeLinked,
+			"user", requestedBy,
+			"requested_by", req.RequestedBy,
+		)
+
+		info := transportInfo{source: src.ID, target: dst, user: requestedBy, requestedBy: req.RequestedBy, uids: req.UIDs}
+		// restaurar versões anteriores no próprio ambiente é rollback
+		info.rollback = src.ID == dst.ID && len(req.Versions) == len(req.UIDs)
+		emitTransportStarted(ctx, hooks, info)
+
+		target := newImportTarget(cfg, pipeline, info, orgID, req.options(), importSource{
+			libraries:   grafanaLibraryLookup(src, orgID),
+			permissions: grafanaPermissionLookup(src, orgID),
+		})
 		results := make([]importBatchResult, 0, len(req.UIDs))
 
 		for _, uid := range req.UIDs {
 			res := importBatchResult{SourceUID: uid}
 
+			// 1a) versão do histórico escolhida
+			if version, ok := req.Versions[uid]; ok {
+				ver, err := srcClient.GetDashboardVersion(ctx, uid, version)
+				if err != nil {
+					res.Status = "error"
+					res.SourceVersion = version
+					res.Message = fmt.Sprintf("source get version %d failed: %s", version, err.Error())
+					results = append(results, res)
+					continue
+				}
+				dash := ver.Data
+				dash["uid"] = uid
+				dash["version"] = version
+				results = append(results, importIntoTarget(ctx, target, uid, dash))
+				continue
+			}
+
 			// 1) GET dashboard do SOURCE
 			getURL := srcBase + "/api/dashboards/uid/" + url.PathEscape(uid)
-			getReq, _ := http.NewRequest(http.MethodGet, getURL, nil)
+			getReq, _ := http.NewRequestWithContext(ctx, http.MethodGet, getURL, nil)
 			getReq.SetBasicAuth(src.User, src.Password)
 			getReq.Header.Set("Accept", "application/json")
 			getReq.Header.Set("X-Grafana-Org-Id", orgID)
 
-			getResp, err := http.DefaultClient.Do(getReq)
+			getResp, err := srcHTTP.Do(getReq)
 			if err != nil {
 				res.Status = "error"
 				res.Message = "source get failed: " + err.Error()
@@ -161,7 +212,7 @@ func ImportDashboardsBatch(cfg *config.Config) http.HandlerFunc {
 
 			if getResp.StatusCode >= 300 {
 				res.Status = "error"
-				res.Message = fmt.Sprintf("source get failed: grafana api %d: %s", getResp.StatusCode, string(bodyBytes))
+				res.Message = fmt.Sprintf("source get failed: grafana api %d: %s", getResp.StatusCode, grafana.ErrorMessage(bodyBytes))
 				results = append(results, res)
 				continue
 			}
@@ -180,324 +231,492 @@ func ImportDashboardsBatch(cfg *config.Config) http.HandlerFunc {
 				continue
 			}
 
-			// pega título (fallback de search)
-			title, _ := dashGet.Dashboard["title"].(string)
+			// 2) IMPORT no TARGET + 3) RBAC
+			results = append(results, importIntoTarget(ctx, target, uid, dashGet.Dashboard))
+		}
 
-			// Sanitização p/ import
-			dashGet.Dashboard["id"] = nil
-			dashGet.Dashboard["version"] = 0
+		finishTransport(ctx, hooks, catalog, info, results)
 
-			// 2) IMPORT no TARGET
-			importPayload := grafanaImportReq{
-				Dashboard: dashGet.Dashboard,
-				FolderUID: req.FolderUID, // "" = General
-				Overwrite: true,
-			}
+		writeJSON(w, http.StatusOK, results)
+	}
+}
 
-			b, _ := json.Marshal(importPayload)
-			importURL := dstBase + "/api/dashboards/db"
-			impReq, _ := http.NewRequest(http.MethodPost, importURL, bytes.NewReader(b))
-			impReq.Header.Set("Content-Type", "application/json")
-			impReq.Header.Set("Accept", "application/json")
-			impReq.Header.Set("X-Grafana-Org-Id", orgID)
-			impReq.SetBasicAuth(dst.User, dst.Password)
+// importBatchFromGit importa os UIDs a partir de um ref do repo Git (mesmo pipeline de destino)
+func importBatchFromGit(w http.ResponseWriter, r *http.Request, cfg *config.Config, pipeline ImportPipeline, jobs *transport.Jobs, repo *gitrepo.Repo, hooks *webhooks.Dispatcher, catalog *cache.Catalog, req importBatchRequest, orgID string) {
+	if repo == nil {
+		writeError(w, http.StatusNotImplemented, gitDisabledMsg)
+		return
+	}
+	dst := cfg.GetEnvironment(req.TargetEnv)
+	if dst == nil {
+		writeError(w, http.StatusBadRequest, "unknown targetEnv")
+		return
+	}
 
-			impResp, err := http.DefaultClient.Do(impReq)
-			if err != nil {
-				res.Status = "error"
-				res.Message = "target import failed: " + err.Error()
-				results = append(results, res)
-				continue
-			}
-			impBody, _ := io.ReadAll(impResp.Body)
-			_ = impResp.Body.Close()
+	files, commit, err := repo.LoadDashboards(r.Context(), req.SourceRef, req.UIDs)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-			if impResp.StatusCode >= 300 {
-				res.Status = "error"
-				res.Message = fmt.Sprintf("target import failed: grafana api %d: %s", impResp.StatusCode, string(impBody))
-				results = append(results, res)
-				continue
-			}
+	ctx, done, ok := startTransportJob(w, r, jobs)
+	if !ok {
+		return
+	}
+	defer done()
+
+	requestedBy := grafanaLoggedUserFromHeaders(r)
+	slog.InfoContext(ctx, "transport started",
+		"source_env", sourceGit,
+		"source_ref", req.SourceRef,
+		"source_commit", commit,
+		"target_env", dst.ID,
+		"folder", req.FolderUID,
+		"uids", len(req.UIDs),
+		"user", requestedBy,
+		"requested_by", req.RequestedBy,
+	)
+
+	info := transportInfo{source: sourceGit, sourceRef: req.SourceRef, target: dst, user: requestedBy, requestedBy: req.RequestedBy, uids: req.UIDs}
+	emitTransportStarted(ctx, hooks, info)
+
+	target := newImportTarget(cfg, pipeline, info, orgID, req.options(), importSource{})
+	results := make([]importBatchResult, 0, len(req.UIDs))
+
+	for _, uid := range req.UIDs {
+		data, found := files[uid]
+		if !found {
+			results = append(results, importBatchResult{
+				SourceUID: uid,
+				Status:    "error",
+				Message:   fmt.Sprintf("dashboard not found in git ref %s", req.SourceRef),
+			})
+			continue
+		}
+		dash, err := transport.ParseDashboardJSON(data)
+		if err != nil {
+			results = append(results, importBatchResult{SourceUID: uid, Status: "error", Message: err.Error()})
+			continue
+		}
+		results = append(results, importIntoTarget(ctx, target, uid, dash))
+	}
 
-			var impOut grafanaImportResp
-			_ = json.Unmarshal(impBody, &impOut)
+	finishTransport(ctx, hooks, catalog, info, results)
 
-			targetUID := impOut.UID
-			if targetUID == "" {
-				targetUID = uid
-			}
-			res.TargetUID = targetUID
+	writeJSON(w, http.StatusOK, results)
+}
 
-			// 3) RBAC: Editor (2) pro(s) requestedBy
-			requesters := parseRequestedByList(req.RequestedBy)
-			if len(requesters) == 0 {
-				res.Status = "warning"
-				res.Message = "import ok; rbac skipped (requestedBy vazio)"
-				results = append(results, res)
-				continue
-			}
+func (req importBatchRequest) options() importOptions {
+	return importOptions{folderUID: req.FolderUID, provenance: req.Provenance, copyPermissions: req.CopyPermissions, grants: req.Grants}
+}
 
-			// resolve dashID
-			dashID, warn := resolveDashboardIDAfterImport(dstBase, dst.User, dst.Password, orgID, targetUID, impOut.ID, title)
-			if warn != "" {
-				res.Status = "warning"
-				res.Message = "import ok; rbac failed (" + warn + ")"
-				results = append(results, res)
-				continue
-			}
+// validateVersions: versions só com sourceEnv, p/ UIDs da lista e > 0
+func validateVersions(req importBatchRequest) error {
+	if len(req.Versions) == 0 {
+		return nil
+	}
+	if req.SourceRef != "" {
+		return fmt.Errorf("versions requires sourceEnv")
+	}
+	for uid, v := range req.Versions {
+		if !slices.Contains(req.UIDs, uid) {
+			return fmt.Errorf("versions: uid %s is not in uids", uid)
+		}
+		if v <= 0 {
+			return fmt.Errorf("versions: invalid version %d for uid %s", v, uid)
+		}
+	}
+	return nil
+}
 
-			// ✅ aplica todos os usuários em UM POST só
-			warn = applyDashboardPermissionsByIDMulti(dstBase, dst.User, dst.Password, orgID, dashID, requesters, 2)
-			if warn != "" {
-				res.Status = "warning"
-				res.Message = "import ok; rbac failed (" + warn + ")"
-				results = append(results, res)
-				continue
+// mergeUIDs junta as listas sem duplicar, mantendo a ordem
+func mergeUIDs(lists ...[]string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, list := range lists {
+		for _, uid := range list {
+			if !seen[uid] {
+				seen[uid] = true
+				out = append(out, uid)
 			}
-
-			res.Status = "ok"
-			results = append(results, res)
 		}
-
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(results)
 	}
+	return out
 }
 
-// resolveDashboardIDAfterImport tenta descobrir o dashID do destino usando:
-// 1) impID (resposta do POST /api/dashboards/db)
-// 2) GET /api/dashboards/uid/<uid> -> meta.id
-// 3) /api/search?type=dash-db&query=<title> e casa uid
-func resolveDashboardIDAfterImport(dstBase, adminUser, adminPass, orgID, dashboardUID string, impID int, title string) (int, string) {
-	if impID > 0 {
-		return impID, ""
-	}
+// importTarget agrupa o que é preciso p/ importar no ambiente de destino.
+// É o mesmo pipeline p/ qualquer origem (outro Grafana, upload de arquivo/bundle).
+type importTarget struct {
+	env         *config.Environment
+	base        string
+	hc          *http.Client
+	client      *grafana.Client // na org do request
+	orgID       string
+	folderUID   string // "" = General
+	requesters  []string
+	libraries   *librarySync
+	validator   *targetValidator
+	info        transportInfo
+	message     *transport.MessageTemplate
+	provenance  bool              // carimba transported-from + transportProvenance
+	permissions *permissionCopier // nil = não copia permissões da origem
+	resolver    *principalResolver
+	grants      []permissionGrantRequest
+	policy      grantPolicy // vale p/ requestedBy, grants e copiadas
+}
 
-	getURL := dstBase + "/api/dashboards/uid/" + url.PathEscape(dashboardUID)
-	greq, _ := http.NewRequest(http.MethodGet, getURL, nil)
-	greq.SetBasicAuth(adminUser, adminPass)
-	greq.Header.Set("Accept", "application/json")
-	greq.Header.Set("X-Grafana-Org-Id", orgID)
+// importOptions são as opções do request que valem p/ todo o batch
+type importOptions struct {
+	folderUID       string // "" = General
+	provenance      *bool  // nil = PROVENANCE_STAMP
+	copyPermissions bool
+	grants          []permissionGrantRequest // já validados contra a política do destino
+}
 
-	gresp, err := http.DefaultClient.Do(greq)
-	if err != nil {
-		return 0, "get dash by uid: " + err.Error()
-	}
-	gbody, _ := io.ReadAll(gresp.Body)
-	_ = gresp.Body.Close()
+// importSource é o que se lê da origem além do dashboard.
+// Campos nil = origem não tem (upload/git).
+type importSource struct {
+	libraries   libraryLookup    // nil = só usa os library panels que já existem no destino
+	permissions permissionLookup // p/ copyPermissions
+}
 
-	if gresp.StatusCode >= 300 {
-		return 0, fmt.Sprintf("get dash by uid grafana api %d: %s", gresp.StatusCode, string(gbody))
+func newImportTarget(cfg *config.Config, pipeline ImportPipeline, info transportInfo, orgID string, opts importOptions, src importSource) importTarget {
+	dst := info.target
+	stamp := cfg.ProvenanceStamp
+	if opts.provenance != nil {
+		stamp = *opts.provenance
 	}
-
-	var dashGet grafanaDashboardGetResp
-	if err := json.Unmarshal(gbody, &dashGet); err != nil {
-		return 0, "decode dash by uid: " + err.Error()
+	client := grafana.ClientForEnvironment(dst).WithOrg(orgID)
+	resolver := newPrincipalResolver(client)
+	policy := newGrantPolicy(cfg, dst.ID)
+	var perms *permissionCopier
+	if opts.copyPermissions {
+		perms = newPermissionCopier(resolver, src.permissions, cfg.PermissionMapping, policy)
 	}
-
-	if dashGet.Meta.ID > 0 {
-		return dashGet.Meta.ID, ""
+	return importTarget{
+		info:        info,
+		message:     pipeline.Message,
+		provenance:  stamp,
+		permissions: perms,
+		resolver:    resolver,
+		grants:      opts.grants,
+		policy:      policy,
+		libraries:   newLibrarySync(client, src.libraries),
+		validator:   newTargetValidator(cfg, pipeline, dst, client),
+		env:         dst,
+		base:        stringsTrimRightSlash(dst.URL),
+		hc:          grafana.HTTPClient(dst.ID),
+		client:      client,
+		orgID:       orgID,
+		folderUID:   opts.folderUID,
+		requesters:  parseRequestedByList(info.requestedBy),
 	}
+}
 
-	title = strings.TrimSpace(title)
-	if title == "" {
-		return 0, "dash id not found (meta.id=0 and title empty)"
+// importIntoTarget valida o dashboard contra o destino (erros bloqueiam, warnings
+// anotam o resultado) e importa. sourceUID é só p/ identificar o item no resultado.
+func importIntoTarget(ctx context.Context, t importTarget, sourceUID string, dash map[string]any) importBatchResult {
+	sourceVersion := transport.DashboardVersion(dash)
+
+	issues := t.validator.validate(ctx, dash)
+	if transport.HasErrors(issues) {
+		title, _ := dash["title"].(string)
+		return importBatchResult{
+			SourceUID:     sourceUID,
+			Title:         title,
+			SourceVersion: sourceVersion,
+			Status:        "error",
+			Message:       "validation failed: " + issuesMessage(issues, transport.SeverityError),
+			Issues:        issues,
+		}
 	}
 
-	type searchItem struct {
-		ID  int    `json:"id"`
-		UID string `json:"uid"`
+	res := importDashboard(ctx, t, sourceUID, dash)
+	res.Issues = issues
+
+	if warn := issuesMessage(issues, transport.SeverityWarning); warn != "" {
+		switch res.Status {
+		case "ok":
+			res.Status = "warning"
+			res.Message = "import ok; validation warnings (" + warn + ")"
+		case "warning":
+			res.Message += "; validation warnings (" + warn + ")"
+		}
 	}
-	searchURL := dstBase + "/api/search?type=dash-db&query=" + url.QueryEscape(title)
-	sreq, _ := http.NewRequest(http.MethodGet, searchURL, nil)
-	sreq.SetBasicAuth(adminUser, adminPass)
-	sreq.Header.Set("Accept", "application/json")
-	sreq.Header.Set("X-Grafana-Org-Id", orgID)
+	return res
+}
 
-	sresp, err := http.DefaultClient.Do(sreq)
+// importDashboard sanitiza o dashboard, importa no destino e aplica o RBAC
+// (Editor p/ requestedBy)
+func importDashboard(ctx context.Context, t importTarget, sourceUID string, dash map[string]any) importBatchResult {
+	res := importBatchResult{SourceUID: sourceUID}
+	dst := t.env
+
+	// pega título (fallback de search) e versão da origem (antes da sanitização zerar)
+	title, _ := dash["title"].(string)
+	res.Title = title
+	sourceVersion := transport.DashboardVersion(dash)
+	res.SourceVersion = sourceVersion
+
+	// 1) library panels antes do dashboard (senão os panels ficam quebrados no destino)
+	libs, err := t.libraries.ensure(ctx, dash, t.folderUID)
+	res.LibraryPanels = libs
 	if err != nil {
-		return 0, "search by title: " + err.Error()
+		res.Status = "error"
+		res.Message = err.Error()
+		return res
 	}
-	sbody, _ := io.ReadAll(sresp.Body)
-	_ = sresp.Body.Close()
 
-	if sresp.StatusCode >= 300 {
-		return 0, fmt.Sprintf("search by title grafana api %d: %s", sresp.StatusCode, string(sbody))
+	// procedência (o hash é do dashboard como veio da origem)
+	if t.provenance {
+		by := t.info.user
+		if by == "" {
+			by = t.info.requestedBy
+		}
+		stamped, err := transport.StampProvenance(dash, transport.Provenance{
+			SourceEnv:     t.info.source,
+			SourceRef:     t.info.sourceRef,
+			SourceUID:     sourceUID,
+			SourceVersion: sourceVersion,
+			TransportedAt: time.Now().UTC(),
+			TransportedBy: by,
+			JobID:         logging.JobID(ctx),
+		})
+		if err != nil {
+			res.Status = "error"
+			res.Message = "provenance: " + err.Error()
+			return res
+		}
+		dash = stamped
 	}
 
-	var items []searchItem
-	if err := json.Unmarshal(sbody, &items); err != nil {
-		return 0, "decode search by title: " + err.Error()
+	// Sanitização p/ import
+	dash = transport.SanitizeDashboard(dash)
+
+	// 2) IMPORT no TARGET
+	importPayload := grafanaImportReq{
+		Dashboard: dash,
+		FolderUID: t.folderUID, // "" = General
+		Overwrite: true,
+		Message: t.message.Render(transport.MessageData{
+			SourceEnv:     t.info.source,
+			SourceRef:     t.info.sourceRef,
+			SourceVersion: sourceVersion,
+			TargetEnv:     dst.ID,
+			UID:           sourceUID,
+			Title:         title,
+			User:          t.info.user,
+			RequestedBy:   t.info.requestedBy,
+			JobID:         logging.JobID(ctx),
+		}),
 	}
 
-	for _, it := range items {
-		if it.UID == dashboardUID && it.ID > 0 {
-			return it.ID, ""
-		}
-	}
+	b, _ := json.Marshal(importPayload)
+	importURL := t.base + "/api/dashboards/db"
+	impReq, _ := http.NewRequestWithContext(ctx, http.MethodPost, importURL, bytes.NewReader(b))
+	impReq.Header.Set("Content-Type", "application/json")
+	impReq.Header.Set("Accept", "application/json")
+	impReq.Header.Set("X-Grafana-Org-Id", t.orgID)
+	impReq.SetBasicAuth(dst.User, dst.Password)
 
-	return 0, "dash id not found (meta.id=0 and search-by-title did not match uid)"
-}
+	impResp, err := t.hc.Do(impReq)
+	if err != nil {
+		res.Status = "error"
+		res.Message = "target import failed: " + err.Error()
+		return res
+	}
+	impBody, _ := io.ReadAll(impResp.Body)
+	_ = impResp.Body.Close()
 
-// ✅ aplica permissão no dashboard por ID para VÁRIOS usuários,
-// preservando tudo que já existe e garantindo userId com permission desejada.
-// Faz 1 GET + 1 POST (não tem sobrescrita por chamada).
-func applyDashboardPermissionsByIDMulti(dstBase, adminUser, adminPass, orgID string, dashID int, loginOrEmails []string, permission int) string {
-	// 1) resolve todos os userIds
-	userIDs := make([]int, 0, len(loginOrEmails))
-	failed := make([]string, 0)
-
-	seenIDs := map[int]struct{}{}
-	for _, who := range loginOrEmails {
-		who = strings.TrimSpace(who)
-		if who == "" {
-			continue
-		}
+	if impResp.StatusCode >= 300 {
+		res.Status = "error"
+		res.Message = fmt.Sprintf("target import failed: grafana api %d: %s", impResp.StatusCode, grafana.ErrorMessage(impBody))
+		return res
+	}
 
-		lookupURL := dstBase + "/api/users/lookup?loginOrEmail=" + url.QueryEscape(who)
-		lreq, _ := http.NewRequest(http.MethodGet, lookupURL, nil)
-		lreq.SetBasicAuth(adminUser, adminPass)
-		lreq.Header.Set("Accept", "application/json")
-		lreq.Header.Set("X-Grafana-Org-Id", orgID)
+	var impOut grafanaImportResp
+	_ = json.Unmarshal(impBody, &impOut)
 
-		lresp, err := http.DefaultClient.Do(lreq)
-		if err != nil {
-			failed = append(failed, who+" (lookup err: "+err.Error()+")")
-			continue
-		}
-		lbody, _ := io.ReadAll(lresp.Body)
-		_ = lresp.Body.Close()
+	targetUID := impOut.UID
+	if targetUID == "" {
+		targetUID = sourceUID
+	}
+	res.TargetUID = targetUID
 
-		if lresp.StatusCode >= 300 {
-			failed = append(failed, fmt.Sprintf("%s (lookup api %d)", who, lresp.StatusCode))
-			continue
-		}
+	// 3) RBAC: Editor (2) pro(s) requestedBy + grants do request + permissões copiadas da origem
+	requesters, extra, notes := t.resolvePermissions(ctx, sourceUID, &res)
 
-		var u grafanaUserLookupResp
-		if err := json.Unmarshal(lbody, &u); err != nil {
-			failed = append(failed, who+" (lookup decode err)")
-			continue
-		}
-		if u.ID == 0 {
-			failed = append(failed, who+" (userId=0)")
-			continue
+	if len(requesters) == 0 && len(extra) == 0 {
+		if len(t.requesters) == 0 {
+			notes = append(notes, "rbac skipped (requestedBy vazio)")
 		}
+		res.Status = "warning"
+		res.Message = "import ok; " + strings.Join(notes, "; ")
+		return res
+	}
 
-		if _, ok := seenIDs[u.ID]; ok {
-			continue
-		}
-		seenIDs[u.ID] = struct{}{}
-		userIDs = append(userIDs, u.ID)
+	// aplica pelo uid, na API de permissões que o destino suporta
+	if warn := t.applyPermissions(ctx, targetUID, requesters, extra); warn != "" {
+		res.Status = "warning"
+		res.Message = "import ok; rbac failed (" + warn + ")"
+		return res
 	}
 
-	if len(userIDs) == 0 {
-		if len(failed) > 0 {
-			return "no valid users; failed: " + strings.Join(failed, "; ")
-		}
-		return "no valid users"
+	if len(notes) > 0 {
+		res.Status = "warning"
+		res.Message = "import ok; " + strings.Join(notes, "; ")
+		return res
 	}
 
-	// 2) GET current permissions
-	permURL := fmt.Sprintf("%s/api/dashboards/id/%d/permissions", dstBase, dashID)
-	pgreq, _ := http.NewRequest(http.MethodGet, permURL, nil)
-	pgreq.SetBasicAuth(adminUser, adminPass)
-	pgreq.Header.Set("Accept", "application/json")
-	pgreq.Header.Set("X-Grafana-Org-Id", orgID)
+	res.Status = "ok"
+	return res
+}
 
-	pgresp, err := http.DefaultClient.Do(pgreq)
-	if err != nil {
-		return "get perms: " + err.Error()
+// resolvePermissions passa os requestedBy pela política do destino, junta os grants do
+// request e as permissões copiadas da origem (já resolvidos no destino) e preenche
+// res.Permissions. Devolve os requestedBy permitidos; notes vão p/ a mensagem (warning).
+func (t importTarget) resolvePermissions(ctx context.Context, sourceUID string, res *importBatchResult) ([]string, []permissionGrant, []string) {
+	requesters := t.requesters
+	denied := ""
+	if len(requesters) > 0 {
+		denied = t.policy.check("user", grafana.PermissionEdit)
 	}
-	pgbody, _ := io.ReadAll(pgresp.Body)
-	_ = pgresp.Body.Close()
-
-	if pgresp.StatusCode >= 300 {
-		return fmt.Sprintf("get perms grafana api %d: %s", pgresp.StatusCode, string(pgbody))
+	if len(t.grants) == 0 && t.permissions == nil && denied == "" {
+		return requesters, nil, nil
 	}
 
-	var current grafanaDashPermGetResp
-	_ = json.Unmarshal(pgbody, &current)
+	report := &permissionReport{Unresolved: []permissionPrincipal{}}
+	var out []permissionGrant
+	var notes []string
 
-	// 3) monta payload preservando entradas + garante TODOS userIds
-	itemsOut := make([]map[string]any, 0, len(current.Permissions)+len(userIDs))
-
-	// set dos users que já existem no current
-	existingUser := map[int]int{}
-	for _, p := range current.Permissions {
-		if p.UserID != 0 {
-			existingUser[p.UserID] = p.Permission
+	if denied != "" {
+		for _, who := range requesters {
+			report.Unresolved = append(report.Unresolved, permissionPrincipal{Type: "user", Source: who, Permission: grafana.PermissionEdit, Reason: denied})
 		}
+		requesters = nil
 	}
 
-	// preserva tudo que já existe (users/teams/roles)
-	for _, p := range current.Permissions {
-		if p.UserID != 0 {
-			// se for um dos users alvo, força permission desejada
-			if _, ok := seenIDs[p.UserID]; ok {
-				itemsOut = append(itemsOut, map[string]any{
-					"userId":     p.UserID,
-					"permission": permission,
-				})
-			} else {
-				itemsOut = append(itemsOut, map[string]any{
-					"userId":     p.UserID,
-					"permission": p.Permission,
-				})
-			}
-			continue
-		}
-		if p.TeamID != 0 {
-			itemsOut = append(itemsOut, map[string]any{
-				"teamId":     p.TeamID,
-				"permission": p.Permission,
-			})
-			continue
-		}
-		if p.Role != "" {
-			itemsOut = append(itemsOut, map[string]any{
-				"role":       p.Role,
-				"permission": p.Permission,
-			})
-			continue
-		}
+	if len(t.grants) > 0 {
+		grants, granted, unresolved := t.resolver.resolveGrants(ctx, t.grants)
+		out = append(out, grants...)
+		report.Granted = granted
+		report.Unresolved = append(report.Unresolved, unresolved...)
 	}
 
-	// adiciona os users alvo que ainda não existiam
-	for _, id := range userIDs {
-		if _, ok := existingUser[id]; ok {
-			continue
+	if t.permissions != nil {
+		grants, copied, err := t.permissions.resolve(ctx, sourceUID)
+		if err != nil {
+			report.Error = err.Error()
+			notes = append(notes, "copy permissions failed ("+err.Error()+")")
+		} else {
+			out = append(out, grants...)
+			report.Copied = copied.Copied
+			report.Unresolved = append(report.Unresolved, copied.Unresolved...)
 		}
-		itemsOut = append(itemsOut, map[string]any{
-			"userId":     id,
-			"permission": permission,
-		})
 	}
 
-	payload := map[string]any{"items": itemsOut}
-	b, _ := json.Marshal(payload)
-
-	// 4) POST permissions (1 vez)
-	ppreq, _ := http.NewRequest(http.MethodPost, permURL, bytes.NewReader(b))
-	ppreq.SetBasicAuth(adminUser, adminPass)
-	ppreq.Header.Set("Content-Type", "application/json")
-	ppreq.Header.Set("Accept", "application/json")
-	ppreq.Header.Set("X-Grafana-Org-Id", orgID)
+	if n := len(report.Unresolved); n > 0 {
+		notes = append(notes, fmt.Sprintf("%d principal(s) unresolved", n))
+	}
+	res.Permissions = report
+	return requesters, out, notes
+}
 
-	ppresp, err := http.DefaultClient.Do(ppreq)
-	if err != nil {
-		return "post perms: " + err.Error()
+// applyPermissions dá Editor aos requesters (já filtrados pela política) e aplica os
+// extras (grants/copiadas). Mesmo principal em mais de uma fonte => fica o maior nível. "" = ok.
+func (t importTarget) applyPermissions(ctx context.Context, dashboardUID string, requesters []string, extra []permissionGrant) string {
+	var items []grafana.PermissionItem
+	index := map[permissionGrant]int{} // principal (Permission zerado) -> posição em items
+	want := func(g permissionGrant) {
+		key := g
+		key.Permission = 0
+		if i, ok := index[key]; ok {
+			items[i].Permission = max(items[i].Permission, g.Permission)
+			return
+		}
+		index[key] = len(items)
+		items = append(items, grafana.PermissionItem{UserID: g.UserID, TeamID: g.TeamID, Role: g.Role, Permission: g.Permission})
 	}
-	ppbody, _ := io.ReadAll(ppresp.Body)
-	_ = ppresp.Body.Close()
 
-	if ppresp.StatusCode >= 300 {
-		return fmt.Sprintf("post perms grafana api %d: %s", ppresp.StatusCode, string(ppbody))
+	var failed []string
+	for _, who := range requesters {
+		id, err := t.resolver.userID(ctx, who)
+		switch {
+		case err != nil:
+			failed = append(failed, who+" (lookup err: "+err.Error()+")")
+		case id == 0:
+			failed = append(failed, who+" (not found)")
+		default:
+			want(permissionGrant{UserID: id, Permission: grafana.PermissionEdit})
+		}
+	}
+	for _, g := range extra {
+		want(g)
 	}
 
+	if len(items) == 0 {
+		return "no valid users; failed: " + strings.Join(failed, "; ")
+	}
+	if err := t.client.SetDashboardPermissions(ctx, dashboardUID, items); err != nil {
+		return err.Error()
+	}
 	// se alguns falharam no lookup, retorna warning (mas não falha tudo)
 	if len(failed) > 0 {
 		return "some users failed lookup: " + strings.Join(failed, "; ")
 	}
-
 	return ""
 }
+
+// startTransportJob registra o job (503 se o servidor está desligando) e devolve
+// o ctx do job: com job id e SEM cancelamento quando o cliente desconecta — um
+// batch cortado no meio deixa dashboard importado sem RBAC. O limite fica por
+// conta do shutdown.
+func startTransportJob(w http.ResponseWriter, r *http.Request, jobs *transport.Jobs) (context.Context, func(), bool) {
+	jobID := logging.NewID()
+	done, err := jobs.Start(jobID)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return nil, nil, false
+	}
+
+	ctx := logging.WithJobID(context.WithoutCancel(r.Context()), jobID)
+	w.Header().Set("X-Transport-Job-Id", jobID)
+	return ctx, done, true
+}
+
+// finishTransport contabiliza métricas, loga o resultado de cada dashboard + resumo,
+// invalida o cache do destino e dispara o webhook de fim (succeeded / rolled_back / partially_failed / failed)
+func finishTransport(ctx context.Context, hooks *webhooks.Dispatcher, catalog *cache.Catalog, info transportInfo, results []importBatchResult) {
+	sourceID, targetID := info.source, info.target.ID
+	catalog.Invalidate(ctx, targetID)
+
+	counts := map[string]int{}
+	for _, res := range results {
+		metrics.TransportsTotal.WithLabelValues(sourceID, targetID, res.Status).Inc()
+		counts[res.Status]++
+
+		level := slog.LevelInfo
+		if res.Status != "ok" {
+			level = slog.LevelWarn
+		}
+		slog.Log(ctx, level, "dashboard transported",
+			"source_env", sourceID,
+			"target_env", targetID,
+			"uid", res.SourceUID,
+			"target_uid", res.TargetUID,
+			"status", res.Status,
+			"message", res.Message,
+		)
+	}
+
+	slog.InfoContext(ctx, "transport finished",
+		"source_env", sourceID,
+		"target_env", targetID,
+		"user", info.user,
+		"ok", counts["ok"],
+		"warning", counts["warning"],
+		"error", counts["error"],
+	)
+
+	hooks.Emit(ctx, info.finishedEvent(results, counts))
+}
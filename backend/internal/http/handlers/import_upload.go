@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"dashboard-transporter/internal/cache"
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/grafana"
+	"dashboard-transporter/internal/transport"
+	"dashboard-transporter/internal/webhooks"
+)
+
+// tamanho máximo do multipart inteiro (vários arquivos/bundles)
+const maxUploadSize = 100 << 20
+
+// sourceUpload é o "ambiente de origem" de imports feitos por arquivo (métricas/logs)
+const sourceUpload = "upload"
+
+// ImportDashboardsUpload importa dashboards enviados como arquivo:
+// POST /api/v1/dashboards/import/upload (multipart/form-data)
+//
+// Campos:
+//   - targetEnv, folderUid, requestedBy: iguais ao import batch
+//   - datasources (opcional): JSON {"DS_PROM": "<uid ou nome no destino>"} p/ exports externos
+//   - files: um ou mais .json de dashboard e/ou .zip de bundle do transporter
+func ImportDashboardsUpload(cfg *config.Config, pipeline ImportPipeline, jobs *transport.Jobs, hooks *webhooks.Dispatcher, catalog *cache.Catalog) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		orgID := getOrgIDFromRequest(r)
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid multipart form: "+err.Error())
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		targetEnv := r.FormValue("targetEnv")
+		folderUID := r.FormValue("folderUid")
+		requestedByList := r.FormValue("requestedBy")
+
+		if targetEnv == "" {
+			writeError(w, http.StatusBadRequest, "targetEnv is required")
+			return
+		}
+		dst := cfg.GetEnvironment(targetEnv)
+		if dst == nil {
+			writeError(w, http.StatusBadRequest, "unknown targetEnv")
+			return
+		}
+
+		// provenance=true|false (vazio = PROVENANCE_STAMP)
+		var provenance *bool
+		if v := strings.TrimSpace(r.FormValue("provenance")); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "provenance must be true or false")
+				return
+			}
+			provenance = &b
+		}
+
+		dsMapping := map[string]string{}
+		if v := strings.TrimSpace(r.FormValue("datasources")); v != "" {
+			if err := json.Unmarshal([]byte(v), &dsMapping); err != nil {
+				writeError(w, http.StatusBadRequest, "datasources must be a JSON object {inputName: datasourceUid}")
+				return
+			}
+		}
+
+		files := append(r.MultipartForm.File["files"], r.MultipartForm.File["file"]...)
+		if len(files) == 0 {
+			writeError(w, http.StatusBadRequest, "no files uploaded (use field \"files\")")
+			return
+		}
+
+		var items []transport.UploadedDashboard
+		for _, fh := range files {
+			got, err := readUploadedFile(fh)
+			if err != nil {
+				items = append(items, transport.UploadedDashboard{Source: fh.Filename, Err: err})
+				continue
+			}
+			items = append(items, got...)
+		}
+
+		ctx, done, ok := startTransportJob(w, r, jobs)
+		if !ok {
+			return
+		}
+		defer done()
+
+		requestedBy := grafanaLoggedUserFromHeaders(r)
+		slog.InfoContext(ctx, "transport started",
+			"source_env", sourceUpload,
+			"target_env", dst.ID,
+			"folder", folderUID,
+			"files", len(files),
+			"dashboards", len(items),
+			"user", requestedBy,
+			"requested_by", requestedByList,
+		)
+
+		uids := make([]string, 0, len(items))
+		for _, it := range items {
+			uids = append(uids, it.ID())
+		}
+		info := transportInfo{source: sourceUpload, target: dst, user: requestedBy, requestedBy: requestedByList, uids: uids}
+		emitTransportStarted(ctx, hooks, info)
+
+		// datasources do destino só são buscados se algum arquivo for export externo
+		var targetDS []grafana.DataSource
+		var targetDSErr error
+		for _, it := range items {
+			if it.Err == nil && len(transport.Inputs(it.Dashboard)) > 0 {
+				targetDS, targetDSErr = grafana.ClientForEnvironment(dst).WithOrg(orgID).ListDataSources(ctx)
+				break
+			}
+		}
+
+		// library panels: dos bundles (library-panels/) e do "__elements" de exports externos
+		libraries := map[string]*grafana.LibraryElement{}
+		for _, it := range items {
+			for uid, el := range it.LibraryPanels {
+				libraries[uid] = el
+			}
+			if it.Err == nil {
+				for uid, el := range transport.ExternalLibraryElements(it.Dashboard) {
+					libraries[uid] = el
+				}
+			}
+		}
+
+		target := newImportTarget(cfg, pipeline, info, orgID, importOptions{folderUID: folderUID, provenance: provenance}, importSource{
+			libraries: mapLibraryLookup(libraries),
+		})
+		results := make([]importBatchResult, 0, len(items))
+		seen := map[string]bool{}
+
+		for _, it := range items {
+			id := it.ID()
+			res := importBatchResult{SourceUID: id, Status: "error"}
+
+			if it.Err != nil {
+				res.Message = it.Source + ": " + it.Err.Error()
+				results = append(results, res)
+				continue
+			}
+			if seen[id] {
+				res.Message = it.Source + ": duplicate dashboard uid in upload"
+				results = append(results, res)
+				continue
+			}
+			seen[id] = true
+
+			dash := it.Dashboard
+			if len(transport.Inputs(dash)) > 0 {
+				if targetDSErr != nil {
+					res.Message = "list target datasources: " + targetDSErr.Error()
+					results = append(results, res)
+					continue
+				}
+				resolved, err := transport.ResolveInputs(dash, targetDS, dsMapping)
+				if err != nil {
+					res.Message = it.Source + ": " + err.Error()
+					results = append(results, res)
+					continue
+				}
+				dash = resolved
+			}
+
+			results = append(results, importIntoTarget(ctx, target, id, dash))
+		}
+
+		finishTransport(ctx, hooks, catalog, info, results)
+
+		writeJSON(w, http.StatusOK, results)
+	}
+}
+
+// readUploadedFile lê um arquivo do multipart: .zip (bundle) ou .json (1 dashboard)
+func readUploadedFile(fh *multipart.FileHeader) ([]transport.UploadedDashboard, error) {
+	if fh.Size > maxUploadSize {
+		return nil, fmt.Errorf("file too large")
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if transport.IsZip(data) {
+		_, items, err := transport.ReadBundle(data)
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			items[i].Source = fh.Filename + ":" + items[i].Source
+		}
+		return items, nil
+	}
+
+	if len(data) > transport.MaxDashboardFileSize {
+		return nil, fmt.Errorf("file too large")
+	}
+	dash, err := transport.ParseDashboardJSON(data)
+	return []transport.UploadedDashboard{{Source: fh.Filename, Dashboard: dash, Err: err}}, nil
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/grafana"
+	"dashboard-transporter/internal/transport"
+)
+
+// libraryLookup busca um library panel na origem (nil, nil = origem não tem)
+type libraryLookup func(ctx context.Context, uid string) (*grafana.LibraryElement, error)
+
+// grafanaLibraryLookup lê da API do ambiente de origem (na org do request)
+func grafanaLibraryLookup(src *config.Environment, orgID string) libraryLookup {
+	client := grafana.ClientForEnvironment(src).WithOrg(orgID)
+	return func(ctx context.Context, uid string) (*grafana.LibraryElement, error) {
+		el, err := client.GetLibraryElement(ctx, uid)
+		if grafana.IsNotFound(err) {
+			return nil, nil
+		}
+		return el, err
+	}
+}
+
+// mapLibraryLookup lê de library panels já carregados (bundle / __elements)
+func mapLibraryLookup(elements map[string]*grafana.LibraryElement) libraryLookup {
+	return func(_ context.Context, uid string) (*grafana.LibraryElement, error) {
+		return elements[uid], nil
+	}
+}
+
+// librarySync copia os library panels p/ o destino antes do dashboard.
+// Cada uid é sincronizado uma vez por transporte (vários dashboards podem usar o mesmo).
+type librarySync struct {
+	target *grafana.Client
+	lookup libraryLookup // nil = origem sem library panels (ex. git): só aceita os que já existem
+
+	mu   sync.Mutex
+	done map[string]libraryPanelResult
+}
+
+// target é o client do destino já na org do dashboard
+func newLibrarySync(target *grafana.Client, lookup libraryLookup) *librarySync {
+	return &librarySync{
+		target: target,
+		lookup: lookup,
+		done:   map[string]libraryPanelResult{},
+	}
+}
+
+// ensure garante os library panels do dashboard no destino (no folder do dashboard).
+// Devolve o resultado de cada um e erro se algum falhou (o dashboard não deve ser importado).
+func (s *librarySync) ensure(ctx context.Context, dash map[string]any, folderUID string) ([]libraryPanelResult, error) {
+	refs := transport.LibraryPanelRefs(dash)
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]libraryPanelResult, 0, len(refs))
+	var firstErr error
+	for _, ref := range refs {
+		res, ok := s.done[ref.UID]
+		if !ok {
+			res = s.sync(ctx, ref, folderUID)
+			s.done[ref.UID] = res
+		}
+		out = append(out, res)
+		if res.Status == "error" && firstErr == nil {
+			firstErr = fmt.Errorf("library panel %s: %s", ref.UID, res.Message)
+		}
+	}
+	return out, firstErr
+}
+
+func (s *librarySync) sync(ctx context.Context, ref transport.LibraryPanelRef, folderUID string) libraryPanelResult {
+	res := libraryPanelResult{UID: ref.UID, Name: ref.Name, Status: "error"}
+
+	var src *grafana.LibraryElement
+	if s.lookup != nil {
+		el, err := s.lookup(ctx, ref.UID)
+		if err != nil {
+			res.Message = "source get failed: " + err.Error()
+			return res
+		}
+		src = el
+	}
+
+	existing, err := s.target.GetLibraryElement(ctx, ref.UID)
+	if err != nil && !grafana.IsNotFound(err) {
+		res.Message = "target get failed: " + err.Error()
+		return res
+	}
+	if err != nil {
+		existing = nil
+	}
+
+	switch {
+	case src == nil && existing == nil:
+		res.Message = "not found in source nor target"
+		return res
+
+	case src == nil:
+		res.Name = existing.Name
+		res.Status = "existing"
+		return res
+
+	case existing == nil:
+		if _, err := s.target.CreateLibraryElement(ctx, *src, folderUID); err != nil {
+			res.Message = "create failed: " + err.Error()
+			return res
+		}
+		res.Name = src.Name
+		res.Status = "created"
+		return res
+
+	case existing.Name == src.Name && existing.FolderUID == folderUID && reflect.DeepEqual(existing.Model, src.Model):
+		res.Name = src.Name
+		res.Status = "unchanged"
+		return res
+
+	default:
+		if _, err := s.target.UpdateLibraryElement(ctx, *src, folderUID, existing.Version); err != nil {
+			res.Message = "update failed: " + err.Error()
+			return res
+		}
+		res.Name = src.Name
+		res.Status = "updated"
+		return res
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/grafana"
+	"dashboard-transporter/internal/lint"
+	"dashboard-transporter/internal/transport"
+)
+
+// LintDashboards aplica as regras de lint do ambiente de destino (as mesmas do gate do import):
+// POST /api/v1/dashboards/lint
+// {"targetEnv":"prd","dashboards":[{...}]} ou {"targetEnv":"prd","sourceEnv":"dev","uids":["a"]}
+func LintDashboards(cfg *config.Config, linter *lint.Engine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req lintRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json body")
+			return
+		}
+		if req.TargetEnv != "" && cfg.GetEnvironment(req.TargetEnv) == nil {
+			writeError(w, http.StatusBadRequest, "unknown targetEnv")
+			return
+		}
+		if len(req.Dashboards) == 0 && len(req.UIDs) == 0 {
+			writeError(w, http.StatusBadRequest, "dashboards or uids is required")
+			return
+		}
+
+		out := lintOut{
+			TargetEnv: req.TargetEnv,
+			Rules:     linter.Rules(req.TargetEnv),
+			Results:   make([]lintResult, 0, len(req.Dashboards)+len(req.UIDs)),
+		}
+		if out.Rules == nil {
+			out.Rules = []*lint.Rule{}
+		}
+
+		for _, dash := range req.Dashboards {
+			out.Results = append(out.Results, lintDashboard(linter, req.TargetEnv, dash))
+		}
+
+		if len(req.UIDs) > 0 {
+			src := cfg.GetEnvironment(req.SourceEnv)
+			if src == nil {
+				writeError(w, http.StatusBadRequest, "sourceEnv is required with uids")
+				return
+			}
+			client := grafana.ClientForEnvironment(src).WithOrg(getOrgIDFromRequest(r))
+			for _, uid := range req.UIDs {
+				full, err := client.GetDashboardFull(r.Context(), uid)
+				if err != nil {
+					out.Results = append(out.Results, lintResult{UID: uid, Issues: []transport.Issue{}, Error: err.Error()})
+					continue
+				}
+				out.Results = append(out.Results, lintDashboard(linter, req.TargetEnv, full.Dashboard))
+			}
+		}
+
+		writeJSON(w, http.StatusOK, out)
+	}
+}
+
+func lintDashboard(linter *lint.Engine, env string, dash map[string]any) lintResult {
+	uid, _ := dash["uid"].(string)
+	title, _ := dash["title"].(string)
+	issues := linter.Lint(dash, env)
+	if issues == nil {
+		issues = []transport.Issue{}
+	}
+	return lintResult{UID: uid, Title: title, Passed: !transport.HasErrors(issues), Issues: issues}
+}
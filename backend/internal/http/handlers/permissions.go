@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/grafana"
+)
+
+// permissionLookup lê as permissões do dashboard na origem
+type permissionLookup func(ctx context.Context, uid string) ([]grafana.DashboardPermission, error)
+
+// grafanaPermissionLookup lê da API do ambiente de origem (na org do request)
+func grafanaPermissionLookup(src *config.Environment, orgID string) permissionLookup {
+	client := grafana.ClientForEnvironment(src).WithOrg(orgID)
+	return client.GetDashboardPermissions
+}
+
+// permissionGrant é uma permissão já resolvida no destino (um principal por item)
+type permissionGrant struct {
+	UserID     int
+	TeamID     int
+	Role       string
+	Permission int
+}
+
+// roles aceitos nas permissões de dashboard
+var grantRoles = []string{"Viewer", "Editor"}
+
+// ---------- política por ambiente ----------
+
+// grantPolicy aplica a config.GrantPolicy do ambiente de destino
+type grantPolicy struct {
+	env      string
+	maxLevel int
+	types    []string // vazio = todos
+}
+
+func newGrantPolicy(cfg *config.Config, envID string) grantPolicy {
+	p := grantPolicy{env: envID, maxLevel: grafana.PermissionAdmin}
+	if cp, ok := cfg.GrantPolicies[envID]; ok {
+		if lvl, ok := grafana.PermissionLevel(cp.MaxLevel); ok {
+			p.maxLevel = lvl
+		}
+		p.types = cp.Types
+	}
+	return p
+}
+
+// check devolve o motivo da violação ("" = permitido)
+func (p grantPolicy) check(typ string, level int) string {
+	if len(p.types) > 0 && !slices.Contains(p.types, typ) {
+		return fmt.Sprintf("%s grants are not allowed in %s", typ, p.env)
+	}
+	if level > p.maxLevel {
+		return fmt.Sprintf("%s grants are not allowed in %s (max %s)", grafana.PermissionName(level), p.env, grafana.PermissionName(p.maxLevel))
+	}
+	return ""
+}
+
+// validateGrants confere formato e política dos grants do request (antes de iniciar o job)
+func validateGrants(grants []permissionGrantRequest, policy grantPolicy) error {
+	for i, g := range grants {
+		if strings.TrimSpace(g.Name) == "" {
+			return fmt.Errorf("grants[%d]: name is required", i)
+		}
+		level, ok := grafana.PermissionLevel(g.Level)
+		if !ok {
+			return fmt.Errorf("grants[%d]: level must be View, Edit or Admin", i)
+		}
+		switch g.Type {
+		case "user", "team":
+		case "role":
+			if !slices.Contains(grantRoles, g.Name) {
+				return fmt.Errorf("grants[%d]: role must be Viewer or Editor", i)
+			}
+		default:
+			return fmt.Errorf("grants[%d]: type must be user, team or role", i)
+		}
+		if reason := policy.check(g.Type, level); reason != "" {
+			return fmt.Errorf("grants[%d]: %s", i, reason)
+		}
+	}
+	return nil
+}
+
+// ---------- resolução de principals no destino ----------
+
+// principalResolver resolve users e teams no destino, uma vez por transporte
+type principalResolver struct {
+	target *grafana.Client
+
+	mu    sync.Mutex
+	users map[string]int // login/email -> id (0 = não existe)
+	teams map[string]int // nome -> id (0 = não existe)
+}
+
+func newPrincipalResolver(target *grafana.Client) *principalResolver {
+	return &principalResolver{
+		target: target,
+		users:  map[string]int{},
+		teams:  map[string]int{},
+	}
+}
+
+// userID devolve 0, nil se o user não existe no destino
+func (r *principalResolver) userID(ctx context.Context, loginOrEmail string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := strings.ToLower(loginOrEmail)
+	if id, ok := r.users[key]; ok {
+		return id, nil
+	}
+	id, err := r.target.GetUserID(ctx, loginOrEmail)
+	if err != nil && !grafana.IsNotFound(err) {
+		return 0, err // erro transitório não fica no cache
+	}
+	r.users[key] = id
+	return id, nil
+}
+
+// teamID devolve 0, nil se o team não existe no destino
+func (r *principalResolver) teamID(ctx context.Context, name string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := strings.ToLower(name)
+	if id, ok := r.teams[key]; ok {
+		return id, nil
+	}
+	team, err := r.target.FindTeamByName(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	id := 0
+	if team != nil {
+		id = team.ID
+	}
+	r.teams[key] = id
+	return id, nil
+}
+
+// resolveGrants resolve os grants do request no destino (já validados)
+func (r *principalResolver) resolveGrants(ctx context.Context, grants []permissionGrantRequest) ([]permissionGrant, []permissionPrincipal, []permissionPrincipal) {
+	var out []permissionGrant
+	granted := []permissionPrincipal{}
+	unresolved := []permissionPrincipal{}
+
+	for _, g := range grants {
+		level, _ := grafana.PermissionLevel(g.Level)
+		pr := permissionPrincipal{Type: g.Type, Source: g.Name, Permission: level}
+		grant := permissionGrant{Permission: level}
+
+		var id int
+		var err error
+		switch g.Type {
+		case "user":
+			id, err = r.userID(ctx, g.Name)
+			grant.UserID = id
+		case "team":
+			id, err = r.teamID(ctx, g.Name)
+			grant.TeamID = id
+		case "role":
+			id, grant.Role = -1, g.Name
+		}
+
+		switch {
+		case err != nil:
+			pr.Reason = "lookup failed: " + err.Error()
+			unresolved = append(unresolved, pr)
+		case id == 0:
+			pr.Reason = g.Type + " not found in target"
+			unresolved = append(unresolved, pr)
+		default:
+			pr.Target = g.Name
+			granted = append(granted, pr)
+			out = append(out, grant)
+		}
+	}
+	return out, granted, unresolved
+}
+
+// ---------- cópia das permissões da origem ----------
+
+// permissionCopier replica as permissões de user/team/role da origem no destino,
+// traduzindo identidades pelo PERMISSION_MAPPING e respeitando a política do destino
+type permissionCopier struct {
+	resolver *principalResolver
+	lookup   permissionLookup
+	mapping  config.PermissionMapping
+	policy   grantPolicy
+}
+
+// newPermissionCopier devolve nil se a origem não tem permissões (upload/git)
+func newPermissionCopier(resolver *principalResolver, lookup permissionLookup, mapping config.PermissionMapping, policy grantPolicy) *permissionCopier {
+	if lookup == nil {
+		return nil
+	}
+	return &permissionCopier{resolver: resolver, lookup: lookup, mapping: mapping, policy: policy}
+}
+
+// resolve lê as permissões próprias do dashboard na origem (as herdadas do folder
+// ficam de fora) e resolve cada principal no destino
+func (c *permissionCopier) resolve(ctx context.Context, sourceUID string) ([]permissionGrant, *permissionReport, error) {
+	perms, err := c.lookup(ctx, sourceUID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := &permissionReport{Copied: []permissionPrincipal{}, Unresolved: []permissionPrincipal{}}
+	var grants []permissionGrant
+	for _, p := range perms {
+		if p.Inherited {
+			continue
+		}
+
+		var pr permissionPrincipal
+		var grant permissionGrant
+		switch {
+		case p.UserID != 0:
+			pr = permissionPrincipal{Type: "user", Source: firstNonEmptyStr(p.UserLogin, p.UserEmail)}
+			grant.UserID, pr.Target, pr.Reason = c.resolveUser(ctx, p)
+		case p.TeamID != 0:
+			pr = permissionPrincipal{Type: "team", Source: p.Team}
+			grant.TeamID, pr.Target, pr.Reason = c.resolveTeam(ctx, p.Team)
+		case p.Role != "":
+			pr = permissionPrincipal{Type: "role", Source: p.Role, Target: p.Role}
+			grant.Role = p.Role
+		default:
+			continue
+		}
+		pr.Permission = p.Permission
+		grant.Permission = p.Permission
+
+		if pr.Reason == "" {
+			pr.Reason = c.policy.check(pr.Type, p.Permission)
+			if pr.Reason != "" {
+				pr.Target = ""
+			}
+		}
+
+		switch {
+		case pr.Reason == "skipped":
+			continue // mapeado p/ vazio: não copia de propósito
+		case pr.Reason != "":
+			report.Unresolved = append(report.Unresolved, pr)
+		default:
+			report.Copied = append(report.Copied, pr)
+			grants = append(grants, grant)
+		}
+	}
+	return grants, report, nil
+}
+
+// resolveUser tenta o mapeamento (por login e por email) e depois login/email iguais
+func (c *permissionCopier) resolveUser(ctx context.Context, p grafana.DashboardPermission) (int, string, string) {
+	var candidates []string
+	for _, key := range []string{p.UserLogin, p.UserEmail} {
+		if key == "" {
+			continue
+		}
+		if mapped, ok := c.mapping.Users[strings.ToLower(key)]; ok {
+			if mapped == "" {
+				return 0, "", "skipped"
+			}
+			candidates = []string{mapped}
+			break
+		}
+		candidates = append(candidates, key)
+	}
+
+	var lastErr error
+	for _, who := range candidates {
+		id, err := c.resolver.userID(ctx, who)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if id != 0 {
+			return id, who, ""
+		}
+	}
+	if lastErr != nil {
+		return 0, "", "lookup failed: " + lastErr.Error()
+	}
+	return 0, "", fmt.Sprintf("user not found in target (%s)", strings.Join(candidates, ", "))
+}
+
+func (c *permissionCopier) resolveTeam(ctx context.Context, name string) (int, string, string) {
+	if mapped, ok := c.mapping.Teams[strings.ToLower(name)]; ok {
+		if mapped == "" {
+			return 0, "", "skipped"
+		}
+		name = mapped
+	}
+
+	id, err := c.resolver.teamID(ctx, name)
+	if err != nil {
+		return 0, "", "lookup failed: " + err.Error()
+	}
+	if id == 0 {
+		return 0, "", fmt.Sprintf("team not found in target (%s)", name)
+	}
+	return id, name, ""
+}
+
+func firstNonEmptyStr(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/grafana"
+	"dashboard-transporter/internal/transport"
+)
+
+// DashboardProvenance lê a procedência carimbada no dashboard e compara com a origem:
+// GET /api/v1/dashboards/provenance?env=prd&uid=abc
+func DashboardProvenance(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		q := r.URL.Query()
+		uid := q.Get("uid")
+		env := cfg.GetEnvironment(q.Get("env"))
+		if env == nil || uid == "" {
+			writeError(w, http.StatusBadRequest, "env and uid are required")
+			return
+		}
+
+		orgID := getOrgIDFromRequest(r)
+		full, err := grafana.ClientForEnvironment(env).WithOrg(orgID).GetDashboardFull(ctx, uid)
+		if err != nil {
+			writeError(w, grafanaErrorStatus(err), "dashboard "+uid+": "+err.Error())
+			return
+		}
+
+		out := provenanceOut{Env: env.ID, UID: uid, Status: "not_stamped"}
+		p, ok := transport.ReadProvenance(full.Dashboard)
+		if !ok {
+			writeJSON(w, http.StatusOK, out)
+			return
+		}
+		out.Stamped = true
+		out.Provenance = p
+
+		targetHash, err := transport.ContentHash(full.Dashboard)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		out.TargetHash = targetHash
+		out.TargetModified = targetHash != p.ContentHash
+
+		out.Source = sourceProvenanceState(ctx, cfg, p, orgID)
+		sourceChanged := out.Source.Hash != p.ContentHash
+		out.MatchesSource = out.Source.Hash != "" && out.Source.Hash == targetHash
+
+		switch {
+		case out.Source.Status == "missing":
+			out.Status = "source_missing"
+		case out.Source.Hash == "":
+			out.Status = "unknown"
+		case out.MatchesSource:
+			out.Status = "in_sync"
+		case out.TargetModified && sourceChanged:
+			out.Status = "diverged"
+		case out.TargetModified:
+			out.Status = "target_modified"
+		default:
+			out.Status = "source_changed"
+		}
+		writeJSON(w, http.StatusOK, out)
+	}
+}
+
+// sourceProvenanceState lê o dashboard atual na origem (só se a origem for um ambiente configurado)
+func sourceProvenanceState(ctx context.Context, cfg *config.Config, p *transport.Provenance, orgID string) *provenanceSource {
+	src := cfg.GetEnvironment(p.SourceEnv)
+	if src == nil {
+		return &provenanceSource{Status: "unknown"} // upload, git ou ambiente removido
+	}
+
+	full, err := grafana.ClientForEnvironment(src).WithOrg(orgID).GetDashboardFull(ctx, p.SourceUID)
+	if grafana.IsNotFound(err) {
+		return &provenanceSource{Status: "missing"}
+	}
+	if err != nil {
+		return &provenanceSource{Status: "error", Error: err.Error()}
+	}
+	hash, err := transport.ContentHash(full.Dashboard)
+	if err != nil {
+		return &provenanceSource{Status: "error", Error: err.Error()}
+	}
+
+	st := &provenanceSource{Status: "unchanged", Version: transport.DashboardVersion(full.Dashboard), Hash: hash}
+	if hash != p.ContentHash {
+		st.Status = "changed"
+	}
+	return st
+}
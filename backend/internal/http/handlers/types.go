@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"time"
+
+	"dashboard-transporter/internal/lint"
+	"dashboard-transporter/internal/transport"
+)
+
+// Tipos de request/response da API pública do backend (/api/v1).
+// Ficam todos aqui p/ serem reaproveitados entre handlers e refletidos
+// no openapi.json (internal/http/openapi.json) — mudou aqui, muda lá.
+
+// errorOut é o corpo de qualquer resposta de erro (4xx/5xx)
+type errorOut struct {
+	Message string `json:"message"`
+}
+
+type folderOut struct {
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+}
+
+type dashboardOut struct {
+	ID         int      `json:"id"`
+	UID        string   `json:"uid"`
+	Title      string   `json:"title"`
+	FolderUID  string   `json:"folderUid"`
+	FolderPath string   `json:"folderPath"` // "Time A/Projeto X" (General = raiz)
+	Tags       []string `json:"tags"`
+	URL        string   `json:"url,omitempty"` // absoluto no Grafana do ambiente
+}
+
+type userOut struct {
+	ID    int    `json:"id"`
+	Email string `json:"email"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+type dependencyTarget struct {
+	Status  string `json:"status"` // missing | outdated | in_sync | unknown
+	Version int    `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type dependencyNodeOut struct {
+	*transport.DependencyNode
+	Target *dependencyTarget `json:"target,omitempty"` // só p/ dashboards que existem na origem
+}
+
+type dependencyEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type dependencyGraphOut struct {
+	SourceEnv string              `json:"sourceEnv"`
+	TargetEnv string              `json:"targetEnv"`
+	Roots     []string            `json:"roots"`
+	Truncated bool                `json:"truncated"`
+	Nodes     []dependencyNodeOut `json:"nodes"`
+	Edges     []dependencyEdge    `json:"edges"`
+	// Closure é o que vai num importBatchRequest com includeLinked=true
+	Closure  []string `json:"closure"`
+	Missing  []string `json:"missing"`
+	Outdated []string `json:"outdated"`
+}
+
+type dashboardVersionOut struct {
+	Version       int       `json:"version"`
+	ParentVersion int       `json:"parentVersion,omitempty"`
+	RestoredFrom  int       `json:"restoredFrom,omitempty"`
+	Created       time.Time `json:"created"`
+	CreatedBy     string    `json:"createdBy"`
+	Message       string    `json:"message,omitempty"`
+}
+
+type provenanceSource struct {
+	Status  string `json:"status"` // unchanged | changed | missing | unknown | error
+	Version int    `json:"version,omitempty"`
+	Hash    string `json:"hash,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type provenanceOut struct {
+	Env        string                `json:"env"`
+	UID        string                `json:"uid"`
+	Stamped    bool                  `json:"stamped"`
+	Provenance *transport.Provenance `json:"provenance,omitempty"`
+	TargetHash string                `json:"targetHash,omitempty"`
+	// TargetModified: o dashboard mudou no ambiente depois do transporte
+	TargetModified bool              `json:"targetModified"`
+	Source         *provenanceSource `json:"source,omitempty"`
+	// MatchesSource: conteúdo atual igual ao atual da origem
+	MatchesSource bool `json:"matchesSource"`
+	// Status: in_sync | target_modified | source_changed | diverged | source_missing | unknown | not_stamped
+	Status string `json:"status"`
+}
+
+type importBatchRequest struct {
+	SourceEnv string `json:"sourceEnv"`
+	// SourceRef (commit, tag ou branch do repo Git) substitui o sourceEnv como origem
+	SourceRef string `json:"sourceRef,omitempty"`
+	// IncludeLinked importa junto os dashboards linkados (/d/<uid>), recursivamente
+	IncludeLinked bool     `json:"includeLinked,omitempty"`
+	TargetEnv     string   `json:"targetEnv"`
+	FolderUID     string   `json:"folderUid"`
+	RequestedBy   string   `json:"requestedBy"` // pode ser lista: "a,b;c\n d"
+	UIDs          []string `json:"uids"`
+	// Versions escolhe uma versão do histórico da origem por UID (ausente = última)
+	Versions map[string]int `json:"versions,omitempty"`
+	// Provenance carimba a procedência no dashboard importado (nil = PROVENANCE_STAMP)
+	Provenance *bool `json:"provenance,omitempty"`
+	// CopyPermissions replica as permissões de user/team/role do dashboard na origem
+	// (identidades traduzidas pelo PERMISSION_MAPPING; só com sourceEnv)
+	CopyPermissions bool `json:"copyPermissions,omitempty"`
+	// Grants são permissões extras aplicadas em cada dashboard importado,
+	// validadas contra a política do destino (PERMISSION_POLICY)
+	Grants []permissionGrantRequest `json:"grants,omitempty"`
+}
+
+type permissionGrantRequest struct {
+	Type  string `json:"type"`  // user | team | role
+	Name  string `json:"name"`  // login/email, nome do team ou Viewer/Editor
+	Level string `json:"level"` // View | Edit | Admin
+}
+
+type importBatchResult struct {
+	SourceUID string `json:"sourceUid"`
+	TargetUID string `json:"targetUid,omitempty"`
+	Title     string `json:"title,omitempty"`
+	// SourceVersion é a versão da origem que foi importada
+	SourceVersion int    `json:"sourceVersion,omitempty"`
+	Status        string `json:"status"`            // ok | warning | error
+	Message       string `json:"message,omitempty"` // detalhes
+	// LibraryPanels copiados p/ o destino antes do dashboard
+	LibraryPanels []libraryPanelResult `json:"libraryPanels,omitempty"`
+	// Issues da validação contra o destino (errors bloqueiam o import)
+	Issues []transport.Issue `json:"issues,omitempty"`
+	// Permissions é o relatório de grants e copyPermissions
+	Permissions *permissionReport `json:"permissions,omitempty"`
+}
+
+type permissionReport struct {
+	Granted    []permissionPrincipal `json:"granted,omitempty"` // grants do request
+	Copied     []permissionPrincipal `json:"copied,omitempty"`  // copiadas da origem
+	Unresolved []permissionPrincipal `json:"unresolved"`
+	Error      string                `json:"error,omitempty"` // falha lendo as permissões da origem
+}
+
+type permissionPrincipal struct {
+	Type       string `json:"type"`   // user | team | role
+	Source     string `json:"source"` // login/email, nome do team ou role (na origem, se copiada)
+	Target     string `json:"target,omitempty"`
+	Permission int    `json:"permission"` // 1 View | 2 Edit | 4 Admin
+	Reason     string `json:"reason,omitempty"`
+}
+
+type libraryPanelResult struct {
+	UID     string `json:"uid"`
+	Name    string `json:"name,omitempty"`
+	Status  string `json:"status"` // created | updated | unchanged | existing | error
+	Message string `json:"message,omitempty"`
+}
+
+type lintRequest struct {
+	// TargetEnv escolhe as regras (as de envs vazio valem p/ todos); vazio = todas as regras
+	TargetEnv  string           `json:"targetEnv"`
+	Dashboards []map[string]any `json:"dashboards,omitempty"`
+	// SourceEnv + UIDs lê os dashboards do Grafana em vez de mandar o JSON
+	SourceEnv string   `json:"sourceEnv,omitempty"`
+	UIDs      []string `json:"uids,omitempty"`
+}
+
+type lintResult struct {
+	UID    string            `json:"uid"`
+	Title  string            `json:"title,omitempty"`
+	Passed bool              `json:"passed"` // sem issues de severidade error
+	Issues []transport.Issue `json:"issues"`
+	Error  string            `json:"error,omitempty"` // falha lendo o dashboard da origem
+}
+
+type lintOut struct {
+	TargetEnv string       `json:"targetEnv,omitempty"`
+	Rules     []*lint.Rule `json:"rules"`
+	Results   []lintResult `json:"results"`
+}
+
+type envReadiness struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Required      bool   `json:"required"`
+	Status        string `json:"status"` // ok | down
+	LatencyMs     int64  `json:"latencyMs"`
+	Version       string `json:"version,omitempty"`
+	Database      string `json:"database,omitempty"`
+	Authenticated bool   `json:"authenticated"`
+	Error         string `json:"error,omitempty"`
+}
+
+type readinessOut struct {
+	Status       string         `json:"status"` // ok | degraded | down | shutting_down
+	Environments []envReadiness `json:"environments"`
+}
+
+type gitExportRequest struct {
+	Env       string   `json:"env"`
+	UIDs      []string `json:"uids"`      // vazio = todos do folderUid (ou do ambiente)
+	FolderUID string   `json:"folderUid"` // inclui subfolders
+	Branch    string   `json:"branch"`    // vazio = GIT_BRANCH
+	Message   string   `json:"message"`   // 1ª linha do commit (opcional)
+}
+
+type gitExportOut struct {
+	Commit     string   `json:"commit,omitempty"`
+	Branch     string   `json:"branch"`
+	Files      []string `json:"files"`
+	Pushed     bool     `json:"pushed"`
+	Unchanged  bool     `json:"unchanged"`
+	Dashboards int      `json:"dashboards"`
+}
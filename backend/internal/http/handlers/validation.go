@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/grafana"
+	"dashboard-transporter/internal/lint"
+	"dashboard-transporter/internal/secrets"
+	"dashboard-transporter/internal/transport"
+)
+
+// ImportPipeline é a parte configurável do pipeline de import (montada no router):
+// checagens antes de cada import (além das técnicas do targetValidator) e a
+// mensagem de versão gravada no destino. Campos nil = desligado.
+type ImportPipeline struct {
+	Lint    *lint.Engine
+	Secrets *secrets.Scanner
+	Message *transport.MessageTemplate
+}
+
+// targetValidator valida os dashboards contra o destino antes do import
+// (checagens técnicas + lint + secrets do ambiente).
+// O que se sabe do destino (datasources, plugins, versão) é lido uma vez por transporte.
+type targetValidator struct {
+	client  *grafana.Client
+	envID   string
+	foreign []string // hostnames dos outros ambientes
+	checks  ImportPipeline
+
+	once     sync.Once
+	info     transport.TargetInfo
+	warnings []transport.Issue // falhas lendo o destino (o check correspondente fica desligado)
+}
+
+// client é o do destino já na org do dashboard (datasources são por org)
+func newTargetValidator(cfg *config.Config, pipeline ImportPipeline, dst *config.Environment, client *grafana.Client) *targetValidator {
+	return &targetValidator{
+		client:  client,
+		envID:   dst.ID,
+		foreign: foreignHosts(cfg, dst),
+		checks:  pipeline,
+	}
+}
+
+// foreignHosts são os hostnames dos ambientes != destino (sem repetir o do destino)
+func foreignHosts(cfg *config.Config, dst *config.Environment) []string {
+	own := envHostname(dst.URL)
+	var out []string
+	for i := range cfg.Environments {
+		env := &cfg.Environments[i]
+		if env.ID == dst.ID {
+			continue
+		}
+		if h := envHostname(env.URL); h != "" && h != own {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+func envHostname(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+func (v *targetValidator) load(ctx context.Context) {
+	v.info.ForeignHosts = v.foreign
+
+	warn := func(code, msg string) {
+		v.warnings = append(v.warnings, transport.Issue{Severity: transport.SeverityWarning, Code: code, Message: msg})
+	}
+
+	if ds, err := v.client.ListDataSources(ctx); err != nil {
+		warn("target_datasources_unavailable", "datasource check skipped: "+err.Error())
+	} else {
+		v.info.DataSources = ds
+	}
+
+	if plugins, err := v.client.ListPlugins(ctx, "panel"); err != nil {
+		warn("target_plugins_unavailable", "panel plugin check skipped: "+err.Error())
+	} else {
+		v.info.PanelPlugins = make(map[string]bool, len(plugins))
+		for _, p := range plugins {
+			v.info.PanelPlugins[p.ID] = true
+		}
+	}
+
+	if h, err := v.client.Health(ctx); err != nil {
+		warn("target_version_unavailable", "schemaVersion check skipped: "+err.Error())
+	} else {
+		v.info.GrafanaVersion = h.Version
+	}
+}
+
+// validate devolve os problemas do dashboard (erros bloqueiam o import)
+func (v *targetValidator) validate(ctx context.Context, dash map[string]any) []transport.Issue {
+	v.once.Do(func() { v.load(ctx) })
+
+	issues := transport.ValidateDashboard(dash, v.info)
+	issues = append(issues, v.checks.Lint.Lint(dash, v.envID)...)
+	issues = append(issues, v.checks.Secrets.Scan(dash, v.envID)...)
+	return append(issues, v.warnings...)
+}
+
+// issuesMessage resume os problemas de uma severidade p/ o message do resultado
+func issuesMessage(issues []transport.Issue, severity string) string {
+	var parts []string
+	for _, is := range issues {
+		if is.Severity == severity {
+			parts = append(parts, is.Message)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
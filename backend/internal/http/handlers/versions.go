@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/grafana"
+)
+
+// maxVersionsLimit é o teto do ?limit= do histórico
+const maxVersionsLimit = 1000
+
+// DashboardVersions lista o histórico de versões de um dashboard (mais novo primeiro):
+// GET /api/v1/dashboards/versions?env=dev&uid=abc&limit=50
+// A versão escolhida vai no importBatchRequest.versions[uid].
+func DashboardVersions(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		uid := q.Get("uid")
+		env := cfg.GetEnvironment(q.Get("env"))
+		if env == nil || uid == "" {
+			writeError(w, http.StatusBadRequest, "env and uid are required")
+			return
+		}
+
+		limit, err := queryInt(q.Get("limit"), 50)
+		if err != nil || limit <= 0 || limit > maxVersionsLimit {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and 1000")
+			return
+		}
+
+		versions, err := grafana.ClientForEnvironment(env).WithOrg(getOrgIDFromRequest(r)).ListDashboardVersions(r.Context(), uid, limit)
+		if err != nil {
+			writeError(w, grafanaErrorStatus(err), "list versions: "+err.Error())
+			return
+		}
+
+		out := make([]dashboardVersionOut, 0, len(versions))
+		for _, v := range versions {
+			out = append(out, dashboardVersionOut{
+				Version:       v.Version,
+				ParentVersion: v.ParentVersion,
+				RestoredFrom:  v.RestoredFrom,
+				Created:       v.Created,
+				CreatedBy:     v.CreatedBy,
+				Message:       v.Message,
+			})
+		}
+		writeJSON(w, http.StatusOK, out)
+	}
+}
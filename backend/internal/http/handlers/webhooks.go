@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/webhooks"
+)
+
+// transportInfo descreve um transporte p/ os webhooks (mesmo dado dos logs "transport started")
+type transportInfo struct {
+	source      string // id do ambiente, "upload" ou "git"
+	sourceRef   string
+	target      *config.Environment
+	user        string // usuário logado no Grafana (headers do plugin)
+	requestedBy string
+	uids        []string
+	rollback    bool // origem = destino e todo uid com versão do histórico
+}
+
+func (t transportInfo) event(typ string) webhooks.Event {
+	uids := t.uids
+	if uids == nil {
+		uids = []string{}
+	}
+	return webhooks.Event{
+		Type:        typ,
+		User:        t.user,
+		RequestedBy: t.requestedBy,
+		SourceEnv:   t.source,
+		SourceRef:   t.sourceRef,
+		TargetEnv:   t.target.ID,
+		TargetURL:   stringsTrimRightSlash(t.target.URL),
+		UIDs:        uids,
+	}
+}
+
+// emitTransportStarted dispara transport.started
+func emitTransportStarted(ctx context.Context, hooks *webhooks.Dispatcher, info transportInfo) {
+	hooks.Emit(ctx, info.event(webhooks.EventStarted))
+}
+
+func (t transportInfo) finishedEvent(results []importBatchResult, counts map[string]int) webhooks.Event {
+	typ := webhooks.EventSucceeded
+	switch {
+	case len(results) > 0 && counts["error"] == len(results):
+		typ = webhooks.EventFailed
+	case counts["error"] > 0:
+		typ = webhooks.EventPartiallyFailed
+	case t.rollback:
+		typ = webhooks.EventRolledBack
+	}
+
+	ev := t.event(typ)
+	ev.Summary = &webhooks.Summary{
+		Total:   len(results),
+		OK:      counts["ok"],
+		Warning: counts["warning"],
+		Error:   counts["error"],
+	}
+	ev.Dashboards = make([]webhooks.Dashboard, 0, len(results))
+	for _, res := range results {
+		d := webhooks.Dashboard{
+			SourceUID: res.SourceUID,
+			TargetUID: res.TargetUID,
+			Title:     res.Title,
+			Status:    res.Status,
+			Message:   res.Message,
+		}
+		if res.TargetUID != "" {
+			d.URL = ev.TargetURL + "/d/" + res.TargetUID
+		}
+		ev.Dashboards = append(ev.Dashboards, d)
+	}
+	return ev
+}
+
+// Webhooks lista os destinos configurados (sem secret)
+func Webhooks(hooks *webhooks.Dispatcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		type endpointOut struct {
+			Name   string   `json:"name"`
+			URL    string   `json:"url"`
+			Events []string `json:"events"`
+		}
+		eps := hooks.Endpoints()
+		out := make([]endpointOut, 0, len(eps))
+		for _, ep := range eps {
+			events := ep.Events
+			if events == nil {
+				events = []string{}
+			}
+			out = append(out, endpointOut{Name: ep.Name, URL: webhookHost(ep.URL), Events: events})
+		}
+		writeJSON(w, http.StatusOK, out)
+	}
+}
+
+// webhookHost esconde path/query (URLs de Slack e afins carregam o token no path)
+func webhookHost(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// WebhookDeliveries devolve o log de entregas (?event=transport.succeeded&webhook=itsm)
+func WebhookDeliveries(hooks *webhooks.Dispatcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		writeJSON(w, http.StatusOK, hooks.Deliveries(q.Get("event"), q.Get("webhook")))
+	}
+}
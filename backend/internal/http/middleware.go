@@ -1,8 +1,14 @@
 package http
 
 import (
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
+
+	"dashboard-transporter/internal/logging"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
 )
 
 // CORS middleware: permite qualquer Origin (reflete o Origin do browser).
@@ -21,7 +27,8 @@ func CORS(next http.Handler) http.Handler {
 
 		w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PUT,DELETE,OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers",
-			"Accept, Authorization, Content-Type, X-CSRF-Token, X-Grafana-Org-Id, X-Grafana-User, X-Grafana-Role, X-Grafana-Email, X-Grafana-Device-Id")
+			"Accept, Authorization, Content-Type, X-CSRF-Token, X-Grafana-Org-Id, X-Grafana-User, X-Grafana-Role, X-Grafana-Email, X-Grafana-Device-Id, X-Request-Id, If-None-Match")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-Id, X-Transport-Job-Id, X-Total-Count, ETag")
 
 		// Preflight
 		if r.Method == http.MethodOptions {
@@ -32,3 +39,68 @@ func CORS(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// RequestID garante um X-Request-Id por requisição (reaproveita o do cliente se vier),
+// coloca no contexto (logs + chamadas ao Grafana) e devolve no response.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSpace(r.Header.Get("X-Request-Id"))
+		if id == "" || len(id) > 128 {
+			id = logging.NewID()
+		}
+
+		w.Header().Set("X-Request-Id", id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+// Deprecated marca as rotas legadas sem versão (RFC 8594) e aponta a sucessora
+// em /api/v1 (/health -> /healthz, que fica fora do versionamento)
+func Deprecated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		successor := APIPrefix + r.URL.Path
+		if r.URL.Path == "/health" {
+			successor = "/healthz"
+		}
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+successor+">; rel=\"successor-version\"")
+		slog.WarnContext(r.Context(), "deprecated route called", "path", r.URL.Path, "successor", successor)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AccessLog registra uma linha estruturada por requisição
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := chimw.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		attrs := []any{
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", ww.Status(),
+			"bytes", ww.BytesWritten(),
+			"duration_ms", time.Since(start).Milliseconds(),
+		}
+		if env := r.URL.Query().Get("env"); env != "" {
+			attrs = append(attrs, "env", env)
+		}
+		if user := grafanaUserFromHeaders(r); user != "" {
+			attrs = append(attrs, "user", user)
+		}
+
+		slog.InfoContext(r.Context(), "http request", attrs...)
+	})
+}
+
+// grafanaUserFromHeaders lê o usuário logado que o proxy do Grafana repassa
+func grafanaUserFromHeaders(r *http.Request) string {
+	for _, h := range []string{"X-Grafana-User", "X-Grafana-Email", "X-WEBAUTH-USER"} {
+		if v := strings.TrimSpace(r.Header.Get(h)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
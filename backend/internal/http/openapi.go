@@ -0,0 +1,77 @@
+package http
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// APIPrefix é o prefixo de todas as rotas de negócio (versionadas)
+const APIPrefix = "/api/v1"
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+// OpenAPI serve o documento OpenAPI 3 (GET /api/v1/openapi.json)
+func OpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openAPISpec)
+}
+
+// VerifyOpenAPI compara as rotas registradas no chi (sob APIPrefix) com os
+// paths/métodos do openapi.json e devolve erro listando o que está faltando
+// em cada lado. É chamado no startup p/ o spec não ficar pra trás.
+func VerifyOpenAPI(routes chi.Routes) error {
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		return fmt.Errorf("openapi.json invalid: %w", err)
+	}
+
+	documented := map[string]bool{}
+	for path, ops := range spec.Paths {
+		for method := range ops {
+			switch method {
+			case "get", "post", "put", "patch", "delete":
+				documented[strings.ToUpper(method)+" "+path] = true
+			}
+		}
+	}
+
+	registered := map[string]bool{}
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if !strings.HasPrefix(route, APIPrefix+"/") {
+			return nil
+		}
+		route = strings.TrimSuffix(strings.TrimPrefix(route, APIPrefix), "/")
+		registered[method+" "+route] = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	for k := range registered {
+		if !documented[k] {
+			problems = append(problems, "not documented: "+k)
+		}
+	}
+	for k := range documented {
+		if !registered[k] {
+			problems = append(problems, "documented but not registered: "+k)
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("openapi.json out of sync with router: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
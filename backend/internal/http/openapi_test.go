@@ -0,0 +1,21 @@
+package http
+
+import (
+	"testing"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/lint"
+	"dashboard-transporter/internal/transport"
+)
+
+// o openapi.json tem que bater com as rotas registradas sob /api/v1
+func TestOpenAPIMatchesRouter(t *testing.T) {
+	linter, err := lint.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := NewRouter(&config.Config{}, transport.NewJobs(), nil, nil, nil, linter, nil)
+	if err := VerifyOpenAPI(router); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,26 +1,83 @@
 package http
 
 import (
-	"net/http"
-
+	"dashboard-transporter/internal/cache"
 	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/drift"
+	"dashboard-transporter/internal/gitrepo"
 	"dashboard-transporter/internal/http/handlers"
+	"dashboard-transporter/internal/lint"
+	"dashboard-transporter/internal/metrics"
+	"dashboard-transporter/internal/secrets"
+	"dashboard-transporter/internal/transport"
+	"dashboard-transporter/internal/webhooks"
 
 	"github.com/go-chi/chi/v5"
 )
 
-func NewRouter(cfg *config.Config) http.Handler {
+// repo == nil => integração Git desligada (rotas /git/* respondem 501)
+// sched == nil => drift agendado desligado (rotas /drift/runs respondem 501)
+// hooks == nil => sem webhooks de saída (listas vazias)
+// linter = regras de lint (LINT_RULES), usadas no /dashboards/lint e como gate do import
+// scanner == nil => varredura de segredos desligada (SECRET_SCAN_ENABLED=false)
+func NewRouter(cfg *config.Config, jobs *transport.Jobs, repo *gitrepo.Repo, sched *drift.Scheduler, hooks *webhooks.Dispatcher, linter *lint.Engine, scanner *secrets.Scanner) chi.Router {
 	r := chi.NewRouter()
 
+	// cache de folders/dashboards por ambiente (invalidado no fim de cada transporte)
+	catalog := cache.NewCatalog(cfg.CacheTTL)
+	pipeline := handlers.ImportPipeline{
+		Lint:    linter,
+		Secrets: scanner,
+		Message: transport.NewMessageTemplate(cfg.ImportMessageTemplate),
+	}
+
 	// ✅ middleware novo (sem options)
+	r.Use(RequestID)
 	r.Use(CORS)
+	r.Use(metrics.Middleware)
+	r.Use(AccessLog)
+
+	// infra (fora do versionamento: probes do k8s/compose e scrape do Prometheus)
+	r.Get("/healthz", handlers.Health)
+	r.Get("/readyz", handlers.Ready(cfg, jobs))
+	r.Handle("/metrics", metrics.Handler())
+
+	// legado sem versão: o build do plugin em dist/ ainda chama essas rotas.
+	// Ficam uma release como alias deprecated das de /api/v1 (fora do openapi.json).
+	r.Group(func(r chi.Router) {
+		r.Use(Deprecated)
+		r.Get("/health", handlers.Health)
+		r.Get("/environments", handlers.Environments(cfg))
+		r.Get("/dashboards", handlers.Dashboards(cfg, catalog))
+		r.Get("/folders", handlers.Folders(cfg, catalog))
+		r.Get("/debug/user/{env}/{username}", handlers.DebugUser(cfg))
+		r.Post("/dashboards/import/batch", handlers.ImportDashboardsBatch(cfg, pipeline, jobs, repo, hooks, catalog))
+	})
 
-	r.Get("/health", handlers.Health)
-	r.Get("/environments", handlers.Environments(cfg))
-	r.Get("/dashboards", handlers.Dashboards(cfg))
-	r.Get("/folders", handlers.Folders(cfg))
-	r.Get("/debug/user/{env}/{username}", handlers.DebugUser(cfg))
-	r.Post("/dashboards/import/batch", handlers.ImportDashboardsBatch(cfg))
+	// API de negócio — toda rota nova entra aqui E no openapi.json
+	r.Route(APIPrefix, func(r chi.Router) {
+		r.Get("/openapi.json", OpenAPI)
+		r.Get("/environments", handlers.Environments(cfg))
+		r.Get("/dashboards", handlers.Dashboards(cfg, catalog))
+		r.Get("/dashboards/export", handlers.ExportDashboard(cfg))
+		r.Get("/dashboards/versions", handlers.DashboardVersions(cfg))
+		r.Get("/dashboards/provenance", handlers.DashboardProvenance(cfg))
+		r.Get("/dashboards/dependencies", handlers.DashboardDependencies(cfg))
+		r.Get("/folders", handlers.Folders(cfg, catalog))
+		r.Get("/drift", handlers.Drift(cfg))
+		r.Get("/drift/runs", handlers.DriftRuns(sched))
+		r.Post("/drift/runs", handlers.TriggerDriftRun(sched))
+		r.Get("/drift/runs/{id}", handlers.DriftRun(sched))
+		r.Get("/export/bundle", handlers.ExportBundle(cfg))
+		r.Get("/debug/user/{env}/{username}", handlers.DebugUser(cfg))
+		r.Post("/dashboards/lint", handlers.LintDashboards(cfg, linter))
+		r.Post("/dashboards/import/batch", handlers.ImportDashboardsBatch(cfg, pipeline, jobs, repo, hooks, catalog))
+		r.Post("/dashboards/import/upload", handlers.ImportDashboardsUpload(cfg, pipeline, jobs, hooks, catalog))
+		r.Post("/git/export", handlers.GitExport(cfg, repo))
+		r.Get("/git/refs", handlers.GitRefs(repo))
+		r.Get("/webhooks", handlers.Webhooks(hooks))
+		r.Get("/webhooks/deliveries", handlers.WebhookDeliveries(hooks))
+	})
 
 	return r
 }
@@ -0,0 +1,215 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/transport"
+)
+
+// tipos de regra (config.LintRule.Type)
+const (
+	TypeTagRequired         = "tag_required"
+	TypeDescriptionRequired = "description_required"
+	TypeTitleForbidden      = "title_forbidden"
+	TypeMinRefresh          = "min_refresh"
+	TypeMaxPanels           = "max_panels"
+)
+
+// Rule é uma regra já validada/compilada
+type Rule struct {
+	ID       string   `json:"id"`
+	Type     string   `json:"type"`
+	Severity string   `json:"severity"`
+	Envs     []string `json:"envs,omitempty"`
+
+	cfg     config.LintRule
+	pattern *regexp.Regexp
+	refresh time.Duration
+	check   func(r *Rule, dash map[string]any) string // "" = passou
+}
+
+// Engine aplica as regras configuradas. nil = sem regras (tudo passa).
+type Engine struct {
+	rules []*Rule
+}
+
+// New compila as regras. Qualquer regra inválida é erro (lista todas): o lint é
+// gate do import e não pode ficar desligado em silêncio.
+func New(rules []config.LintRule) (*Engine, error) {
+	e := &Engine{}
+	var errs []error
+	for _, rc := range rules {
+		r, err := compile(rc)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("lint rule %q (%s): %w", rc.ID, rc.Type, err))
+			continue
+		}
+		e.rules = append(e.rules, r)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	for _, r := range e.rules {
+		slog.Info("lint rule configured", "rule", r.ID, "type", r.Type, "severity", r.Severity, "envs", r.Envs)
+	}
+	return e, nil
+}
+
+func compile(rc config.LintRule) (*Rule, error) {
+	r := &Rule{ID: rc.ID, Type: rc.Type, Severity: strings.ToLower(rc.Severity), Envs: rc.Envs, cfg: rc}
+	switch r.Severity {
+	case "":
+		r.Severity = transport.SeverityError
+	case transport.SeverityError, transport.SeverityWarning:
+	default:
+		return nil, fmt.Errorf("invalid severity %q", rc.Severity)
+	}
+
+	switch rc.Type {
+	case TypeTagRequired, TypeTitleForbidden:
+		if rc.Pattern == "" {
+			return nil, fmt.Errorf("pattern is required")
+		}
+		re, err := regexp.Compile(rc.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("pattern: %w", err)
+		}
+		r.pattern = re
+		if rc.Type == TypeTagRequired {
+			r.check = checkTagRequired
+		} else {
+			r.check = checkTitleForbidden
+		}
+	case TypeDescriptionRequired:
+		r.check = checkDescriptionRequired
+	case TypeMinRefresh:
+		d, ok := ParseRefresh(rc.Value)
+		if !ok || d <= 0 {
+			return nil, fmt.Errorf("invalid value %q (expected duration like 30s)", rc.Value)
+		}
+		r.refresh = d
+		r.check = checkMinRefresh
+	case TypeMaxPanels:
+		if rc.Max <= 0 {
+			return nil, fmt.Errorf("max must be > 0")
+		}
+		r.check = checkMaxPanels
+	default:
+		return nil, fmt.Errorf("unknown type %q", rc.Type)
+	}
+	return r, nil
+}
+
+// Rules devolve as regras que valem p/ o ambiente de destino ("" = todas)
+func (e *Engine) Rules(env string) []*Rule {
+	if e == nil {
+		return nil
+	}
+	var out []*Rule
+	for _, r := range e.rules {
+		if env == "" || r.appliesTo(env) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func (r *Rule) appliesTo(env string) bool {
+	if len(r.Envs) == 0 {
+		return true
+	}
+	for _, e := range r.Envs {
+		if e == env {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint aplica as regras do ambiente de destino ("" = todas) no dashboard.
+// O Code do issue é o id da regra.
+func (e *Engine) Lint(dash map[string]any, env string) []transport.Issue {
+	var issues []transport.Issue
+	for _, r := range e.Rules(env) {
+		msg := r.check(r, dash)
+		if msg == "" {
+			continue
+		}
+		if r.cfg.Message != "" {
+			msg = r.cfg.Message
+		}
+		issues = append(issues, transport.Issue{Severity: r.Severity, Code: r.ID, Message: msg})
+	}
+	return issues
+}
+
+func checkTagRequired(r *Rule, dash map[string]any) string {
+	tags, _ := dash["tags"].([]any)
+	for _, t := range tags {
+		if s, ok := t.(string); ok && r.pattern.MatchString(s) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("dashboard must have a tag matching %q", r.pattern.String())
+}
+
+func checkDescriptionRequired(_ *Rule, dash map[string]any) string {
+	if d, _ := dash["description"].(string); strings.TrimSpace(d) != "" {
+		return ""
+	}
+	return "dashboard description is required"
+}
+
+func checkTitleForbidden(r *Rule, dash map[string]any) string {
+	title, _ := dash["title"].(string)
+	if !r.pattern.MatchString(title) {
+		return ""
+	}
+	return fmt.Sprintf("title %q matches forbidden pattern %q", title, r.pattern.String())
+}
+
+func checkMinRefresh(r *Rule, dash map[string]any) string {
+	raw, _ := dash["refresh"].(string) // false/"" = refresh desligado
+	d, ok := ParseRefresh(raw)
+	if !ok || d <= 0 || d >= r.refresh {
+		return ""
+	}
+	return fmt.Sprintf("refresh interval %s is below the minimum %s", raw, r.cfg.Value)
+}
+
+func checkMaxPanels(r *Rule, dash map[string]any) string {
+	n := 0
+	for _, p := range transport.Panels(dash) {
+		if t, _ := p["type"].(string); t != "row" {
+			n++
+		}
+	}
+	if n <= r.cfg.Max {
+		return ""
+	}
+	return fmt.Sprintf("dashboard has %d panels (max %d)", n, r.cfg.Max)
+}
+
+// ParseRefresh entende os intervalos do Grafana ("30s", "5m", "1h", "1d", "1w")
+func ParseRefresh(s string) (time.Duration, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, true
+	}
+	unit := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[s[len(s)-1]]
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if unit == 0 || err != nil {
+		return 0, false
+	}
+	return time.Duration(n) * unit, true
+}
@@ -0,0 +1,176 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/transport"
+)
+
+func TestParseRefresh(t *testing.T) {
+	cases := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"30s", 30 * time.Second, true},
+		{"5m", 5 * time.Minute, true},
+		{"1h", time.Hour, true},
+		{" 1h30m ", 90 * time.Minute, true},
+		{"1d", 24 * time.Hour, true},
+		{"2d", 48 * time.Hour, true},
+		{"1w", 7 * 24 * time.Hour, true},
+		{"", 0, false},
+		{"d", 0, false},
+		{"1x", 0, false},
+		{"abc", 0, false},
+		{"1.5d", 0, false},
+	}
+	for _, c := range cases {
+		got, ok := ParseRefresh(c.in)
+		if got != c.want || ok != c.ok {
+			t.Errorf("ParseRefresh(%q) = %s, %v, want %s, %v", c.in, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	cases := []struct {
+		name    string
+		rule    config.LintRule
+		wantErr string
+	}{
+		{"tag_required", config.LintRule{Type: TypeTagRequired, Pattern: "^team-"}, ""},
+		{"tag_required without pattern", config.LintRule{Type: TypeTagRequired}, "pattern is required"},
+		{"title_forbidden bad regex", config.LintRule{Type: TypeTitleForbidden, Pattern: "("}, "pattern"},
+		{"description_required", config.LintRule{Type: TypeDescriptionRequired, Severity: "WARNING"}, ""},
+		{"min_refresh", config.LintRule{Type: TypeMinRefresh, Value: "1m"}, ""},
+		{"min_refresh in days", config.LintRule{Type: TypeMinRefresh, Value: "1d"}, ""},
+		{"min_refresh invalid", config.LintRule{Type: TypeMinRefresh, Value: "soon"}, "invalid value"},
+		{"min_refresh zero", config.LintRule{Type: TypeMinRefresh, Value: "0s"}, "invalid value"},
+		{"max_panels", config.LintRule{Type: TypeMaxPanels, Max: 10}, ""},
+		{"max_panels zero", config.LintRule{Type: TypeMaxPanels}, "max must be > 0"},
+		{"unknown type", config.LintRule{Type: "nope"}, "unknown type"},
+		{"invalid severity", config.LintRule{Type: TypeDescriptionRequired, Severity: "fatal"}, "invalid severity"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r, err := compile(c.rule)
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("err = %v, want %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.check == nil {
+				t.Error("rule without check")
+			}
+		})
+	}
+
+	r, _ := compile(config.LintRule{Type: TypeDescriptionRequired})
+	if r.Severity != transport.SeverityError {
+		t.Errorf("default severity = %q", r.Severity)
+	}
+	r, _ = compile(config.LintRule{Type: TypeDescriptionRequired, Severity: "Warning"})
+	if r.Severity != transport.SeverityWarning {
+		t.Errorf("severity = %q", r.Severity)
+	}
+}
+
+func TestNewListsAllInvalidRules(t *testing.T) {
+	_, err := New([]config.LintRule{
+		{ID: "a", Type: TypeMaxPanels},
+		{ID: "ok", Type: TypeDescriptionRequired},
+		{ID: "b", Type: "nope"},
+	})
+	if err == nil || !strings.Contains(err.Error(), `"a"`) || !strings.Contains(err.Error(), `"b"`) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func panels(types ...string) []any {
+	out := make([]any, 0, len(types))
+	for _, t := range types {
+		out = append(out, map[string]any{"type": t})
+	}
+	return out
+}
+
+func TestRules(t *testing.T) {
+	cases := []struct {
+		name string
+		rule config.LintRule
+		dash map[string]any
+		pass bool
+	}{
+		{"tag present", config.LintRule{Type: TypeTagRequired, Pattern: "^team-"}, map[string]any{"tags": []any{"x", "team-ops"}}, true},
+		{"tag missing", config.LintRule{Type: TypeTagRequired, Pattern: "^team-"}, map[string]any{"tags": []any{"ops"}}, false},
+		{"no tags", config.LintRule{Type: TypeTagRequired, Pattern: "^team-"}, map[string]any{}, false},
+
+		{"description present", config.LintRule{Type: TypeDescriptionRequired}, map[string]any{"description": "CPU"}, true},
+		{"description blank", config.LintRule{Type: TypeDescriptionRequired}, map[string]any{"description": "  "}, false},
+		{"description missing", config.LintRule{Type: TypeDescriptionRequired}, map[string]any{}, false},
+
+		{"title allowed", config.LintRule{Type: TypeTitleForbidden, Pattern: "(?i)copy|test"}, map[string]any{"title": "CPU"}, true},
+		{"title forbidden", config.LintRule{Type: TypeTitleForbidden, Pattern: "(?i)copy|test"}, map[string]any{"title": "CPU (Copy)"}, false},
+
+		{"refresh above min", config.LintRule{Type: TypeMinRefresh, Value: "1m"}, map[string]any{"refresh": "5m"}, true},
+		{"refresh equal min", config.LintRule{Type: TypeMinRefresh, Value: "1m"}, map[string]any{"refresh": "1m"}, true},
+		{"refresh below min", config.LintRule{Type: TypeMinRefresh, Value: "1m"}, map[string]any{"refresh": "10s"}, false},
+		{"refresh off", config.LintRule{Type: TypeMinRefresh, Value: "1m"}, map[string]any{"refresh": false}, true},
+		{"refresh empty", config.LintRule{Type: TypeMinRefresh, Value: "1m"}, map[string]any{"refresh": ""}, true},
+		{"refresh in days", config.LintRule{Type: TypeMinRefresh, Value: "1d"}, map[string]any{"refresh": "1h"}, false},
+
+		{"panels within max", config.LintRule{Type: TypeMaxPanels, Max: 2}, map[string]any{"panels": panels("graph", "row", "stat")}, true},
+		{"panels over max", config.LintRule{Type: TypeMaxPanels, Max: 2}, map[string]any{"panels": panels("graph", "stat", "table")}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.rule.ID = "r"
+			e, err := New([]config.LintRule{c.rule})
+			if err != nil {
+				t.Fatal(err)
+			}
+			issues := e.Lint(c.dash, "prd")
+			if pass := len(issues) == 0; pass != c.pass {
+				t.Fatalf("pass = %v, want %v (%+v)", pass, c.pass, issues)
+			}
+			if !c.pass && (issues[0].Code != "r" || issues[0].Severity != transport.SeverityError || issues[0].Message == "") {
+				t.Errorf("issue = %+v", issues[0])
+			}
+		})
+	}
+}
+
+func TestLintEnvsAndMessage(t *testing.T) {
+	e, err := New([]config.LintRule{
+		{ID: "prd-only", Type: TypeDescriptionRequired, Envs: []string{"prd"}, Message: "describe it"},
+		{ID: "all", Type: TypeTagRequired, Pattern: "x", Severity: "warning"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dash := map[string]any{"title": "A"}
+
+	if got := e.Lint(dash, "hml"); len(got) != 1 || got[0].Code != "all" || got[0].Severity != transport.SeverityWarning {
+		t.Errorf("hml = %+v", got)
+	}
+	got := e.Lint(dash, "prd")
+	if len(got) != 2 || got[0].Code != "prd-only" || got[0].Message != "describe it" {
+		t.Errorf("prd = %+v", got)
+	}
+	if got := e.Lint(dash, ""); len(got) != 2 {
+		t.Errorf("all envs = %+v", got)
+	}
+
+	var nilEngine *Engine
+	if got := nilEngine.Lint(dash, "prd"); got != nil {
+		t.Errorf("nil engine = %+v", got)
+	}
+}
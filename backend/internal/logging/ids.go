@@ -0,0 +1,13 @@
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// NewID gera um id curto aleatório (16 hex) p/ request/job
+func NewID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
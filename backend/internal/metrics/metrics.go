@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "dashboard_transporter"
+
+var (
+	// TransportsTotal conta dashboards transportados por origem, destino e status (ok | warning | error)
+	TransportsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transports_total",
+		Help:      "Dashboards transportados, por ambiente de origem, destino e status.",
+	}, []string{"source", "target", "status"})
+
+	// JobsInFlight mostra quantos jobs de transporte (batch) estão rodando agora
+	JobsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "jobs_in_flight",
+		Help:      "Jobs de transporte em execução.",
+	})
+
+	// GrafanaRequestDuration mede a latência das chamadas à API do Grafana
+	GrafanaRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grafana_request_duration_seconds",
+		Help:      "Latência das chamadas à API do Grafana, por ambiente e endpoint.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"env", "method", "endpoint", "code"})
+
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Requisições HTTP recebidas pelo backend, por rota chi.",
+	}, []string{"method", "route", "code"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latência das requisições HTTP do backend, por rota chi.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+// Handler expõe as métricas no formato do Prometheus (GET /metrics)
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// statusRecorder guarda o status code escrito pelo handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+// Flush repassa pro writer original (streaming continua funcionando)
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Middleware registra contagem e latência por rota chi (pattern, não a URL crua,
+// pra não explodir cardinalidade com uid/env no path).
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if p := rctx.RoutePattern(); p != "" {
+				route = p
+			}
+		}
+
+		httpRequestsTotal.WithLabelValues(r.Method, route, strconv.Itoa(rec.status)).Inc()
+		httpRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// segmentos da API do Grafana cujo próximo segmento é um identificador
+var grafanaCollections = map[string]bool{
+	"folders":          true,
+	"library-elements": true,
+	"teams":            true,
+	"users":            true,
+	"datasources":      true,
+}
+
+// sub-rotas fixas que NÃO são identificadores (ex: /api/users/lookup)
+var grafanaReserved = map[string]bool{
+	"lookup": true,
+	"search": true,
+	"name":   true,
+	"uid":    true,
+	"id":     true,
+}
+
+// GrafanaEndpoint normaliza o path da API do Grafana p/ usar como label:
+// /api/dashboards/uid/abc?x=1 -> /api/dashboards/uid/:uid
+// /api/dashboards/id/42/permissions -> /api/dashboards/id/:id/permissions
+// /api/access-control/dashboards/abc/builtInRoles/Viewer -> /api/access-control/dashboards/:uid/builtInRoles/:role
+func GrafanaEndpoint(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		if p == "" {
+			continue
+		}
+		if i > 0 {
+			switch parts[i-1] {
+			case "uid":
+				parts[i] = ":uid"
+				continue
+			case "id":
+				parts[i] = ":id"
+				continue
+			case "builtInRoles":
+				parts[i] = ":role"
+				continue
+			case "dashboards":
+				// /api/access-control/dashboards/:uid/...
+				if i > 1 && parts[i-2] == "access-control" {
+					parts[i] = ":uid"
+					continue
+				}
+			}
+			if grafanaCollections[parts[i-1]] && !grafanaReserved[p] {
+				parts[i] = ":uid"
+				continue
+			}
+		}
+		if _, err := strconv.Atoi(p); err == nil {
+			parts[i] = ":id"
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,29 @@
+package metrics
+
+import "testing"
+
+func TestGrafanaEndpoint(t *testing.T) {
+	cases := []struct {
+		path string
+		want string
+	}{
+		{"/api/dashboards/uid/abc?x=1", "/api/dashboards/uid/:uid"},
+		{"/api/dashboards/id/42/permissions", "/api/dashboards/id/:id/permissions"},
+		{"/api/dashboards/uid/abc/versions/3", "/api/dashboards/uid/:uid/versions/:id"},
+		{"/api/folders/f1", "/api/folders/:uid"},
+		{"/api/library-elements/name/CPU", "/api/library-elements/name/CPU"},
+		{"/api/users/lookup?loginOrEmail=bob", "/api/users/lookup"},
+		{"/api/teams/search", "/api/teams/search"},
+		{"/api/datasources/uid/prom", "/api/datasources/uid/:uid"},
+		{"/api/access-control/dashboards/abc", "/api/access-control/dashboards/:uid"},
+		{"/api/access-control/dashboards/abc/users/7", "/api/access-control/dashboards/:uid/users/:uid"},
+		{"/api/access-control/dashboards/abc/teams/3", "/api/access-control/dashboards/:uid/teams/:uid"},
+		{"/api/access-control/dashboards/abc/builtInRoles/Viewer", "/api/access-control/dashboards/:uid/builtInRoles/:role"},
+		{"/api/search", "/api/search"},
+	}
+	for _, c := range cases {
+		if got := GrafanaEndpoint(c.path); got != c.want {
+			t.Errorf("GrafanaEndpoint(%q) = %q, want %q", c.path, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Package notify envia alertas (drift etc.) por webhook genérico, Slack ou email.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/grafana"
+)
+
+const sendTimeout = 10 * time.Second
+
+// Message é o alerta: Subject/Text p/ humanos, Data p/ o webhook genérico
+type Message struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// Notifier é um canal de notificação
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, msg Message) error
+}
+
+// FromConfig monta os canais configurados (pode devolver lista vazia)
+func FromConfig(cfg config.NotifyConfig) []Notifier {
+	var out []Notifier
+	hc := &http.Client{Timeout: sendTimeout}
+
+	if cfg.WebhookURL != "" {
+		out = append(out, &webhook{url: cfg.WebhookURL, hc: hc})
+	}
+	if cfg.SlackWebhookURL != "" {
+		out = append(out, &slack{url: cfg.SlackWebhookURL, hc: hc})
+	}
+	if cfg.SMTP.Enabled() {
+		out = append(out, &email{cfg: cfg.SMTP})
+	}
+	return out
+}
+
+// webhook faz POST do Message inteiro em JSON
+type webhook struct {
+	url string
+	hc  *http.Client
+}
+
+func (w *webhook) Name() string { return "webhook" }
+
+func (w *webhook) Notify(ctx context.Context, msg Message) error {
+	return postJSON(ctx, w.hc, w.url, msg)
+}
+
+// slack manda {"text": ...} (aceito por Slack, Mattermost, Rocket.Chat, Teams via connector)
+type slack struct {
+	url string
+	hc  *http.Client
+}
+
+func (s *slack) Name() string { return "slack" }
+
+func (s *slack) Notify(ctx context.Context, msg Message) error {
+	return postJSON(ctx, s.hc, s.url, map[string]string{
+		"text": "*" + msg.Subject + "*\n" + msg.Text,
+	})
+}
+
+func postJSON(ctx context.Context, hc *http.Client, url string, payload any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %d: %s", resp.StatusCode, grafana.ErrorMessage(body))
+	}
+	return nil
+}
+
+// email envia texto puro pelo SMTP configurado (STARTTLS quando o servidor oferece)
+type email struct {
+	cfg config.SMTPConfig
+}
+
+func (e *email) Name() string { return "email" }
+
+func (e *email) Notify(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if e.cfg.User != "" {
+		auth = smtp.PlainAuth("", e.cfg.User, e.cfg.Password, e.cfg.Host)
+	}
+
+	var b strings.Builder
+	b.WriteString("From: " + e.cfg.From + "\r\n")
+	b.WriteString("To: " + strings.Join(e.cfg.To, ", ") + "\r\n")
+	b.WriteString("Subject: " + strings.ReplaceAll(msg.Subject, "\n", " ") + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Text, "\n", "\r\n"))
+
+	return e.send(ctx, auth, []byte(b.String()))
+}
+
+// send é o smtp.SendMail com prazo: servidor travado não segura o chamador
+// (o scheduler de drift fica bloqueado enquanto notifica)
+func (e *email) send(ctx context.Context, auth smtp.Auth, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(e.cfg.Host, e.cfg.Port))
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	// cancelamento do ctx antes do prazo também derruba a conexão
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, e.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: e.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(e.cfg.From); err != nil {
+		return err
+	}
+	for _, to := range e.cfg.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"dashboard-transporter/internal/config"
+)
+
+func TestEmailHungServerHonorsContext(t *testing.T) {
+	// aceita a conexão e nunca manda o greeting
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	e := &email{cfg: config.SMTPConfig{Host: host, Port: port, From: "a@x", To: []string{"b@x"}}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := e.Notify(ctx, Message{Subject: "s", Text: "t"}); err == nil {
+		t.Fatal("want error from a hung server")
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("Notify took %s", d)
+	}
+}
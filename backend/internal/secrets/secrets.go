@@ -0,0 +1,222 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/transport"
+)
+
+// padrões embutidos (SECRET_SCAN_PATTERNS soma outros)
+var builtinPatterns = []config.SecretPattern{
+	{Name: "aws_access_key", Regex: `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{Name: "aws_secret_key", Regex: `(?i)aws.{0,20}?(?:secret|key).{0,5}?['"=:\s]+[A-Za-z0-9/+=]{40}\b`},
+	{Name: "jwt", Regex: `\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`},
+	{Name: "url_password", Regex: `[A-Za-z][A-Za-z0-9+.-]*://[^/\s:@]+:[^/\s@$]+@`},
+	{Name: "private_key", Regex: `-----BEGIN [A-Z ]*PRIVATE KEY-----`},
+	{Name: "bearer_token", Regex: `(?i)\bbearer\s+[A-Za-z0-9._~+/=-]{16,}`},
+	{Name: "credential_param", Regex: `(?i)\b(?:api[_-]?key|access[_-]?token|token|secret|password|passwd)\s*[:=]\s*["']?[^\s"'&$]{8,}`},
+	{Name: "grafana_token", Regex: `\b(?:glsa|glc)_[A-Za-z0-9+/=_]{32,}`},
+	{Name: "github_token", Regex: `\bgh[pousr]_[A-Za-z0-9]{36,}\b`},
+	{Name: "slack_token", Regex: `\bxox[abprs]-[A-Za-z0-9-]{10,}`},
+}
+
+// chaves cujo valor é identificador aleatório por natureza (uid de dashboard/datasource...)
+var identifierKeys = map[string]bool{"uid": true, "id": true, "hash": true, "version": true, "pluginVersion": true}
+
+var entropyTokenRe = regexp.MustCompile(`[A-Za-z0-9+/=_-]+`)
+
+type pattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// Scanner procura segredos nos valores string do dashboard. nil = desligado.
+type Scanner struct {
+	patterns  []pattern
+	allow     []*regexp.Regexp
+	entropy   float64
+	minLength int
+	protected map[string]bool
+}
+
+// New compila os padrões. Padrão ou allow inválido é erro (lista todos): a varredura é
+// gate do import e não pode perder um padrão em silêncio. Devolve nil se desligado.
+func New(cfg config.SecretScanConfig) (*Scanner, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	s := &Scanner{entropy: cfg.Entropy, minLength: cfg.MinLength, protected: map[string]bool{}}
+	for _, env := range cfg.ProtectedEnvs {
+		s.protected[env] = true
+	}
+
+	var errs []error
+	for _, p := range append(append([]config.SecretPattern{}, builtinPatterns...), cfg.Patterns...) {
+		if p.Name == "" {
+			errs = append(errs, fmt.Errorf("secret pattern %q: name is required", p.Regex))
+			continue
+		}
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("secret pattern %q: %w", p.Name, err))
+			continue
+		}
+		s.patterns = append(s.patterns, pattern{name: p.Name, re: re})
+	}
+	for _, a := range cfg.Allow {
+		re, err := regexp.Compile(a)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("SECRET_SCAN_ALLOW %q: %w", a, err))
+			continue
+		}
+		s.allow = append(s.allow, re)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	slog.Info("secret scan configured", "patterns", len(s.patterns), "entropy", s.entropy, "protected_envs", cfg.ProtectedEnvs)
+	return s, nil
+}
+
+// Finding é um segredo suspeito encontrado
+type Finding struct {
+	Rule   string // nome do padrão ou "high_entropy"
+	Path   string // caminho JSON ($.panels[0].options.content)
+	Masked string // início do valor + "****" (nunca o segredo inteiro)
+}
+
+// Find varre todos os valores string do dashboard
+func (s *Scanner) Find(dash map[string]any) []Finding {
+	if s == nil {
+		return nil
+	}
+	var out []Finding
+	s.walk("$", "", dash, &out)
+	return out
+}
+
+// Scan devolve os achados como issues: error se o destino é protegido, senão warning
+func (s *Scanner) Scan(dash map[string]any, targetEnv string) []transport.Issue {
+	findings := s.Find(dash)
+	if len(findings) == 0 {
+		return nil
+	}
+	sev := transport.SeverityWarning
+	if s.protected[targetEnv] {
+		sev = transport.SeverityError
+	}
+	issues := make([]transport.Issue, 0, len(findings))
+	for _, f := range findings {
+		issues = append(issues, transport.Issue{
+			Severity: sev,
+			Code:     "secret_" + f.Rule,
+			Message:  fmt.Sprintf("possible secret (%s) at %s: %s", f.Rule, f.Path, f.Masked),
+			Path:     f.Path,
+		})
+	}
+	return issues
+}
+
+func (s *Scanner) walk(path, key string, v any, out *[]Finding) {
+	switch t := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys) // ordem estável no relatório
+		for _, k := range keys {
+			s.walk(childPath(path, k), k, t[k], out)
+		}
+	case []any:
+		for i, it := range t {
+			s.walk(path+"["+strconv.Itoa(i)+"]", key, it, out)
+		}
+	case string:
+		s.scanValue(path, key, t, out)
+	}
+}
+
+func (s *Scanner) scanValue(path, key, value string, out *[]Finding) {
+	if value == "" || s.allowed(value) {
+		return
+	}
+	for _, p := range s.patterns {
+		if m := p.re.FindString(value); m != "" && !s.allowed(m) {
+			*out = append(*out, Finding{Rule: p.name, Path: path, Masked: mask(m)})
+			return // um achado por valor basta
+		}
+	}
+
+	if s.entropy <= 0 || identifierKeys[key] || strings.HasSuffix(key, "Uid") || strings.HasSuffix(key, "UID") || strings.HasSuffix(key, "Id") {
+		return
+	}
+	for _, tok := range entropyTokenRe.FindAllString(value, -1) {
+		if len(tok) >= s.minLength && hasLetterAndDigit(tok) && Entropy(tok) >= s.entropy && !s.allowed(tok) {
+			*out = append(*out, Finding{Rule: "high_entropy", Path: path, Masked: mask(tok)})
+			return
+		}
+	}
+}
+
+func (s *Scanner) allowed(v string) bool {
+	for _, re := range s.allow {
+		if re.MatchString(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Entropy é a entropia de Shannon em bits por caractere
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	freq := map[rune]int{}
+	for _, r := range s {
+		freq[r]++
+	}
+	n := float64(len([]rune(s)))
+	var h float64
+	for _, c := range freq {
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+func hasLetterAndDigit(s string) bool {
+	var letter, digit bool
+	for _, r := range s {
+		letter = letter || unicode.IsLetter(r)
+		digit = digit || unicode.IsDigit(r)
+	}
+	return letter && digit
+}
+
+var plainKeyRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func childPath(parent, key string) string {
+	if plainKeyRe.MatchString(key) {
+		return parent + "." + key
+	}
+	return parent + "[" + strconv.Quote(key) + "]"
+}
+
+func mask(s string) string {
+	if len(s) <= 8 {
+		return "****"
+	}
+	return s[:4] + "****"
+}
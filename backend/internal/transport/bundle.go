@@ -0,0 +1,253 @@
+package transport
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// BundleManifestName é o nome do manifest dentro do ZIP
+const BundleManifestName = "manifest.json"
+
+// BundleFormatVersion versiona o layout do ZIP (manifest + pastas)
+const BundleFormatVersion = 1
+
+// BundleManifest descreve o conteúdo de um bundle exportado
+type BundleManifest struct {
+	FormatVersion  int                  `json:"formatVersion"`
+	SourceEnv      string               `json:"sourceEnv"`
+	SourceURL      string               `json:"sourceUrl,omitempty"`
+	GrafanaVersion string               `json:"grafanaVersion,omitempty"`
+	FolderUID      string               `json:"folderUid,omitempty"` // filtro usado no export ("" = tudo)
+	CreatedAt      time.Time            `json:"createdAt"`
+	CreatedBy      string               `json:"createdBy,omitempty"`
+	Dashboards     []BundleDashboard    `json:"dashboards"`
+	LibraryPanels  []BundleLibraryPanel `json:"libraryPanels"`
+	Datasources    []DatasourceRef      `json:"datasources"`
+	Errors         []BundleError        `json:"errors,omitempty"`
+}
+
+// BundleDashboard é uma entrada de dashboard no manifest
+type BundleDashboard struct {
+	UID           string            `json:"uid"`
+	Title         string            `json:"title"`
+	Version       int               `json:"version"`
+	FolderUID     string            `json:"folderUid"`
+	FolderPath    string            `json:"folderPath"`
+	Path          string            `json:"path"`   // caminho do arquivo no ZIP
+	SHA256        string            `json:"sha256"` // checksum do arquivo
+	Datasources   []DatasourceRef   `json:"datasources"`
+	LibraryPanels []LibraryPanelRef `json:"libraryPanels"`
+}
+
+// BundleLibraryPanel é uma entrada de library panel no manifest
+type BundleLibraryPanel struct {
+	UID       string `json:"uid"`
+	Name      string `json:"name"`
+	FolderUID string `json:"folderUid,omitempty"`
+	Version   int    `json:"version"`
+	Path      string `json:"path,omitempty"`
+	SHA256    string `json:"sha256,omitempty"`
+}
+
+// BundleError registra o que não entrou no bundle (o export não para por causa de 1 item)
+type BundleError struct {
+	UID     string `json:"uid"`
+	Kind    string `json:"kind"` // dashboard | libraryPanel
+	Message string `json:"message"`
+}
+
+// BundleWriter escreve o ZIP em streaming; o manifest vai por último (Close)
+type BundleWriter struct {
+	zw       *zip.Writer
+	manifest BundleManifest
+	paths    map[string]bool
+	dsSeen   map[DatasourceRef]bool
+}
+
+// NewBundleWriter começa um bundle em w
+func NewBundleWriter(w io.Writer, m BundleManifest) *BundleWriter {
+	m.FormatVersion = BundleFormatVersion
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now().UTC()
+	}
+	m.Dashboards = []BundleDashboard{}
+	m.LibraryPanels = []BundleLibraryPanel{}
+	m.Datasources = []DatasourceRef{}
+
+	return &BundleWriter{
+		zw:       zip.NewWriter(w),
+		manifest: m,
+		paths:    map[string]bool{},
+		dsSeen:   map[DatasourceRef]bool{},
+	}
+}
+
+// AddDashboard grava dashboards/<folderPath>/<slug>-<uid>.json e registra no manifest
+func (b *BundleWriter) AddDashboard(dash map[string]any, folderUID, folderPath string) error {
+	uid, _ := dash["uid"].(string)
+	title, _ := dash["title"].(string)
+
+	if folderPath == "" {
+		folderPath = "General"
+	}
+
+	name := slugify(title)
+	if name == "" {
+		name = "dashboard"
+	}
+	file := b.uniquePath(path.Join("dashboards", SafeFolderPath(folderPath), name+"-"+SafeSegment(uid)+".json"))
+
+	sum, err := b.writeJSON(file, dash)
+	if err != nil {
+		return err
+	}
+
+	refs := DatasourceRefs(dash)
+	for _, ref := range refs {
+		if !b.dsSeen[ref] {
+			b.dsSeen[ref] = true
+			b.manifest.Datasources = append(b.manifest.Datasources, ref)
+		}
+	}
+
+	libs := LibraryPanelRefs(dash)
+	if refs == nil {
+		refs = []DatasourceRef{}
+	}
+	if libs == nil {
+		libs = []LibraryPanelRef{}
+	}
+
+	b.manifest.Dashboards = append(b.manifest.Dashboards, BundleDashboard{
+		UID:           uid,
+		Title:         title,
+		Version:       intValue(dash["version"]),
+		FolderUID:     folderUID,
+		FolderPath:    folderPath,
+		Path:          file,
+		SHA256:        sum,
+		Datasources:   refs,
+		LibraryPanels: libs,
+	})
+	return nil
+}
+
+// AddLibraryPanel grava library-panels/<uid>.json (o JSON completo do library element)
+func (b *BundleWriter) AddLibraryPanel(uid, name, folderUID string, version int, element any) error {
+	file := b.uniquePath(path.Join("library-panels", SafeSegment(uid)+".json"))
+
+	sum, err := b.writeJSON(file, element)
+	if err != nil {
+		return err
+	}
+
+	b.manifest.LibraryPanels = append(b.manifest.LibraryPanels, BundleLibraryPanel{
+		UID:       uid,
+		Name:      name,
+		FolderUID: folderUID,
+		Version:   version,
+		Path:      file,
+		SHA256:    sum,
+	})
+	return nil
+}
+
+// AddError registra uma falha no manifest
+func (b *BundleWriter) AddError(kind, uid, msg string) {
+	b.manifest.Errors = append(b.manifest.Errors, BundleError{UID: uid, Kind: kind, Message: msg})
+}
+
+// Close grava o manifest e fecha o ZIP
+func (b *BundleWriter) Close() error {
+	if _, err := b.writeJSON(BundleManifestName, b.manifest); err != nil {
+		return err
+	}
+	return b.zw.Close()
+}
+
+func (b *BundleWriter) writeJSON(name string, v any) (string, error) {
+	data, err := marshalIndent(v)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := b.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: b.manifest.CreatedAt,
+	})
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(data); err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (b *BundleWriter) uniquePath(p string) string {
+	if !b.paths[p] {
+		b.paths[p] = true
+		return p
+	}
+	ext := path.Ext(p)
+	base := strings.TrimSuffix(p, ext)
+	for i := 2; ; i++ {
+		cand := fmt.Sprintf("%s-%d%s", base, i, ext)
+		if !b.paths[cand] {
+			b.paths[cand] = true
+			return cand
+		}
+	}
+}
+
+var (
+	reSlug        = regexp.MustCompile(`[^a-z0-9]+`)
+	reUnsafeChars = regexp.MustCompile(`[\\:*?"<>|\x00-\x1f]+`)
+)
+
+func slugify(s string) string {
+	return strings.Trim(reSlug.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// safeSegment deixa um pedaço de caminho seguro p/ ZIP (sem "..", sem chars proibidos no Windows)
+func SafeSegment(s string) string {
+	s = strings.TrimSpace(reUnsafeChars.ReplaceAllString(s, "_"))
+	s = strings.ReplaceAll(s, "/", "_")
+	if s == "" || s == "." || s == ".." {
+		return "_"
+	}
+	return s
+}
+
+// safeFolderPath sanitiza cada nível de "Time A/Projeto X"
+func SafeFolderPath(p string) string {
+	parts := strings.Split(p, "/")
+	for i := range parts {
+		parts[i] = SafeSegment(parts[i])
+	}
+	return path.Join(parts...)
+}
+
+func intValue(v any) int {
+	switch n := v.(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	case json.Number:
+		i, _ := n.Int64()
+		return int(i)
+	}
+	return 0
+}
@@ -0,0 +1,357 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dashboard-transporter/internal/grafana"
+)
+
+// ExportFormat é o formato de saída do export
+type ExportFormat string
+
+const (
+	// FormatRaw é o JSON do dashboard como o Grafana devolve
+	FormatRaw ExportFormat = "raw"
+	// FormatExternal é o "Export for sharing externally" (com __inputs/__requires)
+	FormatExternal ExportFormat = "external"
+	// FormatOperator é um manifest GrafanaDashboard do grafana-operator
+	FormatOperator ExportFormat = "operator"
+	// FormatTerraform é um arquivo .tf com resources grafana_dashboard
+	FormatTerraform ExportFormat = "terraform"
+)
+
+// ParseExportFormat valida o formato (vazio => raw)
+func ParseExportFormat(s string) (ExportFormat, error) {
+	switch f := ExportFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case "":
+		return FormatRaw, nil
+	case FormatRaw, FormatExternal, FormatOperator, FormatTerraform:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown format %q (use raw, external, operator or terraform)", s)
+	}
+}
+
+// ExportItem é um dashboard já buscado na origem, pronto p/ renderizar
+type ExportItem struct {
+	Dashboard   map[string]any
+	FolderUID   string
+	FolderTitle string
+}
+
+// UID devolve o uid do dashboard
+func (it ExportItem) UID() string {
+	v, _ := it.Dashboard["uid"].(string)
+	return v
+}
+
+// ---------- "Export for sharing externally" ----------
+
+// uids "especiais" que não são datasources de verdade
+var builtinDatasources = map[string]bool{
+	"grafana":         true,
+	"-- Grafana --":   true,
+	"-- Mixed --":     true,
+	"-- Dashboard --": true,
+}
+
+var reNonAlnum = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+func datasourceInputName(name string) string {
+	return "DS_" + strings.Trim(strings.ToUpper(reNonAlnum.ReplaceAllString(name, "_")), "_")
+}
+
+// ExternalExport reproduz o "Export for sharing externally" do Grafana:
+// troca cada referência de datasource por ${DS_<NOME>} e monta __inputs/__requires,
+// p/ o JSON poder ser importado em outro Grafana escolhendo os datasources.
+func ExternalExport(dash map[string]any, datasources []grafana.DataSource, grafanaVersion string) map[string]any {
+	out := DeepCopy(dash)
+
+	byUID := make(map[string]grafana.DataSource, len(datasources))
+	byName := make(map[string]grafana.DataSource, len(datasources))
+	for _, ds := range datasources {
+		byUID[ds.UID] = ds
+		byName[ds.Name] = ds
+	}
+
+	resolve := func(ref any) (grafana.DataSource, bool) {
+		switch v := ref.(type) {
+		case string:
+			if v == "" || strings.HasPrefix(v, "$") || builtinDatasources[v] {
+				return grafana.DataSource{}, false
+			}
+			if ds, ok := byName[v]; ok {
+				return ds, true
+			}
+			ds, ok := byUID[v]
+			return ds, ok
+		case map[string]any:
+			uid, _ := v["uid"].(string)
+			if uid == "" || strings.HasPrefix(uid, "$") || builtinDatasources[uid] {
+				return grafana.DataSource{}, false
+			}
+			ds, ok := byUID[uid]
+			return ds, ok
+		}
+		return grafana.DataSource{}, false
+	}
+
+	inputs := map[string]map[string]any{}
+	usedDS := map[string]grafana.DataSource{}
+
+	for _, holder := range DatasourceHolders(out) {
+		ref, ok := holder["datasource"]
+		if !ok {
+			continue
+		}
+		ds, ok := resolve(ref)
+		if !ok {
+			continue
+		}
+
+		name := datasourceInputName(ds.Name)
+		holder["datasource"] = map[string]any{"type": ds.Type, "uid": "${" + name + "}"}
+
+		if _, ok := inputs[name]; !ok {
+			inputs[name] = map[string]any{
+				"name":        name,
+				"label":       ds.Name,
+				"description": "",
+				"type":        "datasource",
+				"pluginId":    ds.Type,
+				"pluginName":  firstNonEmpty(ds.TypeName, ds.Type),
+			}
+		}
+		usedDS[ds.Type] = ds
+
+		// variável de query: valores atuais são do ambiente de origem
+		if holder["type"] == "query" {
+			holder["current"] = map[string]any{}
+			holder["options"] = []any{}
+		}
+	}
+
+	inputList := make([]any, 0, len(inputs))
+	for _, name := range sortedKeys(inputs) {
+		inputList = append(inputList, inputs[name])
+	}
+
+	requires := []any{map[string]any{
+		"type":    "grafana",
+		"id":      "grafana",
+		"name":    "Grafana",
+		"version": grafanaVersion,
+	}}
+	for _, t := range sortedKeys(usedDS) {
+		name := firstNonEmpty(usedDS[t].TypeName, t)
+		requires = append(requires, map[string]any{"type": "datasource", "id": t, "name": name, "version": ""})
+	}
+
+	panelTypes := map[string]bool{}
+	for _, p := range Panels(out) {
+		if t, _ := p["type"].(string); t != "" && t != "row" {
+			panelTypes[t] = true
+		}
+	}
+	for _, t := range sortedKeys(panelTypes) {
+		requires = append(requires, map[string]any{"type": "panel", "id": t, "name": t, "version": ""})
+	}
+
+	out["__inputs"] = inputList
+	out["__elements"] = map[string]any{}
+	out["__requires"] = requires
+	out["id"] = nil
+
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ---------- grafana-operator ----------
+
+// OperatorOptions controla o manifest GrafanaDashboard
+type OperatorOptions struct {
+	Namespace string
+	// InstanceLabel é o label (chave=valor) usado em spec.instanceSelector
+	InstanceLabel string
+	SourceEnv     string
+}
+
+var reK8sName = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// k8sName gera um metadata.name válido (DNS-1123, máx 63)
+func k8sName(s string) string {
+	s = strings.Trim(reK8sName.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if len(s) > 63 {
+		s = strings.TrimRight(s[:63], "-")
+	}
+	if s == "" {
+		s = "dashboard"
+	}
+	return s
+}
+
+// RenderOperator gera um YAML (multi-documento) com um GrafanaDashboard por item
+func RenderOperator(items []ExportItem, opts OperatorOptions) ([]byte, error) {
+	labelKey, labelValue := "dashboards", "grafana"
+	if k, v, ok := strings.Cut(opts.InstanceLabel, "="); ok && k != "" {
+		labelKey, labelValue = k, v
+	}
+
+	var buf bytes.Buffer
+	for i, it := range items {
+		clean := cleanForProvisioning(it.Dashboard)
+		js, err := marshalIndent(clean)
+		if err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		name := k8sName(it.UID())
+		if name == "dashboard" {
+			if t, _ := it.Dashboard["title"].(string); t != "" {
+				name = k8sName(t)
+			}
+		}
+
+		buf.WriteString("apiVersion: grafana.integreatly.org/v1beta1\n")
+		buf.WriteString("kind: GrafanaDashboard\n")
+		buf.WriteString("metadata:\n")
+		buf.WriteString("  name: " + name + "\n")
+		if opts.Namespace != "" {
+			buf.WriteString("  namespace: " + k8sName(opts.Namespace) + "\n")
+		}
+		buf.WriteString("  labels:\n")
+		buf.WriteString("    app.kubernetes.io/managed-by: dashboard-transporter\n")
+		buf.WriteString("  annotations:\n")
+		if opts.SourceEnv != "" {
+			buf.WriteString("    dashboard-transporter/source-env: " + strconv.Quote(opts.SourceEnv) + "\n")
+		}
+		buf.WriteString("    dashboard-transporter/source-uid: " + strconv.Quote(it.UID()) + "\n")
+		buf.WriteString("spec:\n")
+		buf.WriteString("  instanceSelector:\n")
+		buf.WriteString("    matchLabels:\n")
+		buf.WriteString("      " + strconv.Quote(labelKey) + ": " + strconv.Quote(labelValue) + "\n")
+		if it.FolderUID != "" {
+			buf.WriteString("  folderUID: " + strconv.Quote(it.FolderUID) + "\n")
+		}
+		buf.WriteString("  json: |\n")
+		for _, line := range strings.Split(string(js), "\n") {
+			buf.WriteString("    " + line + "\n")
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ---------- Terraform ----------
+
+var reTFName = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// tfName gera um nome de resource válido no HCL
+func tfName(s string) string {
+	s = strings.Trim(reTFName.ReplaceAllString(s, "_"), "_")
+	if s == "" {
+		return "dashboard"
+	}
+	if c := s[0]; c >= '0' && c <= '9' {
+		s = "d_" + s
+	}
+	return strings.ToLower(s)
+}
+
+// RenderTerraform gera um .tf com um resource grafana_dashboard por item
+func RenderTerraform(items []ExportItem) ([]byte, error) {
+	var buf bytes.Buffer
+	seen := map[string]int{}
+
+	for i, it := range items {
+		clean := cleanForProvisioning(it.Dashboard)
+		js, err := marshalIndent(clean)
+		if err != nil {
+			return nil, err
+		}
+
+		name := tfName(it.UID())
+		if n := seen[name]; n > 0 {
+			name = fmt.Sprintf("%s_%d", name, n+1)
+		}
+		seen[name]++
+
+		// heredoc do HCL interpola ${...} e %{...}: escapa p/ ir literal
+		body := strings.NewReplacer("${", "$${", "%{", "%%{").Replace(string(js))
+
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		if t, _ := it.Dashboard["title"].(string); t != "" {
+			buf.WriteString("# " + strings.ReplaceAll(t, "\n", " ") + "\n")
+		}
+		buf.WriteString(`resource "grafana_dashboard" "` + name + "\" {\n")
+		if it.FolderUID != "" {
+			buf.WriteString("  folder      = " + strconv.Quote(it.FolderUID) + "\n")
+		}
+		buf.WriteString("  overwrite   = true\n")
+		buf.WriteString("  config_json = <<-EOT\n")
+		for _, line := range strings.Split(body, "\n") {
+			buf.WriteString("    " + line + "\n")
+		}
+		buf.WriteString("  EOT\n")
+		buf.WriteString("}\n")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// marshalIndent é o json.MarshalIndent sem escapar <, > e & (o JSON vai p/ arquivo, não HTML)
+func marshalIndent(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ProvisioningJSON devolve o JSON (indentado, estável p/ diff) do dashboard sem id/version
+func ProvisioningJSON(dash map[string]any) ([]byte, error) {
+	b, err := marshalIndent(cleanForProvisioning(dash))
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// cleanForProvisioning tira campos que são do ambiente de origem (id/version)
+func cleanForProvisioning(dash map[string]any) map[string]any {
+	out := DeepCopy(dash)
+	delete(out, "id")
+	delete(out, "version")
+	return out
+}
@@ -0,0 +1,32 @@
+package transport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// campos que mudam a cada save/import sem mudar o conteúdo do dashboard
+var volatileKeys = []string{
+	"id", "version", "iteration",
+	"meta", "folderId", "folderUid", "folderTitle",
+	"__inputs", "__elements", "__requires",
+}
+
+// ContentHash devolve o sha256 do dashboard normalizado (sem id/version/meta etc.
+// e sem a procedência carimbada no transporte).
+// Dois ambientes com o mesmo hash têm o mesmo conteúdo, mesmo com versões diferentes.
+func ContentHash(dash map[string]any) (string, error) {
+	norm := DeepCopy(dash)
+	for _, k := range volatileKeys {
+		delete(norm, k)
+	}
+	stripProvenance(norm)
+	// json.Marshal ordena as chaves dos maps => serialização estável
+	b, err := json.Marshal(norm)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
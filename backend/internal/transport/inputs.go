@@ -0,0 +1,167 @@
+package transport
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"dashboard-transporter/internal/grafana"
+)
+
+// DashboardInput é uma entrada de __inputs ("Export for sharing externally")
+type DashboardInput struct {
+	Name     string `json:"name"`
+	Label    string `json:"label"`
+	Type     string `json:"type"` // datasource | constant
+	PluginID string `json:"pluginId"`
+	Value    string `json:"value"`
+}
+
+// Inputs devolve os __inputs do dashboard (vazio se não for export externo)
+func Inputs(dash map[string]any) []DashboardInput {
+	list, _ := dash["__inputs"].([]any)
+	out := make([]DashboardInput, 0, len(list))
+	for _, it := range list {
+		m, ok := it.(map[string]any)
+		if !ok {
+			continue
+		}
+		in := DashboardInput{}
+		in.Name, _ = m["name"].(string)
+		in.Label, _ = m["label"].(string)
+		in.Type, _ = m["type"].(string)
+		in.PluginID, _ = m["pluginId"].(string)
+		in.Value, _ = m["value"].(string)
+		if in.Name != "" {
+			out = append(out, in)
+		}
+	}
+	return out
+}
+
+// ResolveInputs troca os ${DS_...} de um export externo pelos datasources do destino.
+//
+// Para cada input de datasource, na ordem:
+//  1. mapping[input.Name] (uid ou nome do datasource no destino)
+//  2. datasource do destino com o mesmo nome (label) e tipo
+//  3. único datasource do destino com aquele tipo
+//  4. datasource default do destino com aquele tipo
+//
+// Inputs "constant" recebem o próprio value. Sem __inputs, devolve o dashboard como veio.
+func ResolveInputs(dash map[string]any, targetDS []grafana.DataSource, mapping map[string]string) (map[string]any, error) {
+	inputs := Inputs(dash)
+	if len(inputs) == 0 {
+		return dash, nil
+	}
+
+	values := map[string]string{}
+	var unresolved []string
+
+	for _, in := range inputs {
+		switch in.Type {
+		case "constant":
+			values[in.Name] = in.Value
+		case "datasource":
+			ds, ok := pickDatasource(in, targetDS, mapping[in.Name])
+			if !ok {
+				unresolved = append(unresolved, fmt.Sprintf("%s (%s %q)", in.Name, in.PluginID, in.Label))
+				continue
+			}
+			values[in.Name] = ds.UID
+		}
+	}
+
+	if len(unresolved) > 0 {
+		sort.Strings(unresolved)
+		return nil, fmt.Errorf("unresolved datasource inputs: %s", strings.Join(unresolved, ", "))
+	}
+
+	repl := make([]string, 0, len(values)*2)
+	for name, v := range values {
+		repl = append(repl, "${"+name+"}", v)
+	}
+	out := replaceStrings(DeepCopy(dash), strings.NewReplacer(repl...)).(map[string]any)
+
+	delete(out, "__inputs")
+	delete(out, "__elements")
+	delete(out, "__requires")
+	return out, nil
+}
+
+func pickDatasource(in DashboardInput, targetDS []grafana.DataSource, want string) (grafana.DataSource, bool) {
+	if want != "" {
+		for _, ds := range targetDS {
+			if ds.UID == want || ds.Name == want {
+				return ds, true
+			}
+		}
+		return grafana.DataSource{}, false
+	}
+
+	var sameType []grafana.DataSource
+	for _, ds := range targetDS {
+		if ds.Type != in.PluginID {
+			continue
+		}
+		if ds.Name == in.Label {
+			return ds, true
+		}
+		sameType = append(sameType, ds)
+	}
+
+	if len(sameType) == 1 {
+		return sameType[0], true
+	}
+	for _, ds := range sameType {
+		if ds.IsDefault {
+			return ds, true
+		}
+	}
+	return grafana.DataSource{}, false
+}
+
+// replaceStrings aplica r em toda string (valores, não chaves) do JSON
+func replaceStrings(v any, r *strings.Replacer) any {
+	switch t := v.(type) {
+	case string:
+		return r.Replace(t)
+	case map[string]any:
+		for k, val := range t {
+			t[k] = replaceStrings(val, r)
+		}
+		return t
+	case []any:
+		for i, val := range t {
+			t[i] = replaceStrings(val, r)
+		}
+		return t
+	}
+	return v
+}
+
+// ExternalLibraryElements lê os library panels embutidos no "__elements" de um
+// export externo (uid => element). Chamar ANTES do ResolveInputs (que remove o campo).
+func ExternalLibraryElements(dash map[string]any) map[string]*grafana.LibraryElement {
+	raw, _ := dash["__elements"].(map[string]any)
+	out := map[string]*grafana.LibraryElement{}
+	for key, v := range raw {
+		m, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		el := &grafana.LibraryElement{UID: key}
+		if uid, _ := m["uid"].(string); uid != "" {
+			el.UID = uid
+		}
+		el.Name, _ = m["name"].(string)
+		if kind, ok := m["kind"].(float64); ok {
+			el.Kind = int(kind)
+		}
+		el.Model, _ = m["model"].(map[string]any)
+		if el.Model == nil || el.Kind > 1 {
+			continue // só library panels (kind 1) com model
+		}
+		out[el.UID] = el
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"dashboard-transporter/internal/metrics"
+)
+
+// ErrDraining é devolvido quando o servidor está desligando e não aceita novos jobs
+var ErrDraining = errors.New("server is shutting down; not accepting new transports")
+
+// Jobs acompanha os jobs de transporte em execução, p/ o shutdown esperar
+// eles terminarem (em vez de cortar um import de PRD no meio do RBAC).
+type Jobs struct {
+	mu       sync.Mutex
+	running  map[string]struct{}
+	draining bool
+	wg       sync.WaitGroup
+}
+
+func NewJobs() *Jobs {
+	return &Jobs{running: map[string]struct{}{}}
+}
+
+// Start registra um job. Devolve ErrDraining se o shutdown já começou.
+// O done() precisa ser chamado quando o job terminar.
+func (j *Jobs) Start(id string) (done func(), err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.draining {
+		return nil, ErrDraining
+	}
+
+	j.running[id] = struct{}{}
+	j.wg.Add(1)
+	metrics.JobsInFlight.Inc()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			j.mu.Lock()
+			delete(j.running, id)
+			j.mu.Unlock()
+
+			metrics.JobsInFlight.Dec()
+			j.wg.Done()
+		})
+	}, nil
+}
+
+// StopAccepting faz os próximos Start() falharem com ErrDraining
+func (j *Jobs) StopAccepting() {
+	j.mu.Lock()
+	j.draining = true
+	j.mu.Unlock()
+}
+
+// Draining indica se o shutdown já começou
+func (j *Jobs) Draining() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.draining
+}
+
+// Running devolve quantos jobs estão rodando agora
+func (j *Jobs) Running() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.running)
+}
+
+// Wait espera todos os jobs terminarem ou o ctx expirar
+func (j *Jobs) Wait(ctx context.Context) error {
+	finished := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d transport job(s) still running: %w", j.Running(), ctx.Err())
+	}
+}
@@ -0,0 +1,165 @@
+package transport
+
+import (
+	"context"
+	"regexp"
+)
+
+// limites do grafo de dependências (links podem formar ciclos e árvores enormes)
+const (
+	MaxDependencyDepth = 10
+	MaxDependencyNodes = 200
+)
+
+// /d/<uid> em URLs de links (absolutas ou relativas, com ou sem slug/query)
+var dashboardURLRe = regexp.MustCompile(`/d/([A-Za-z0-9_-]{1,40})(?:[/?#]|$)`)
+
+// LinkedDashboardUIDs devolve os UIDs referenciados via /d/<uid> nos links (ver LinkURLs).
+// Links por tag (type "dashboards") não apontam p/ UID e ficam de fora.
+func LinkedDashboardUIDs(dash map[string]any) []string {
+	self, _ := dash["uid"].(string)
+	var out []string
+	seen := map[string]bool{}
+
+	for _, u := range LinkURLs(dash) {
+		for _, m := range dashboardURLRe.FindAllStringSubmatch(u, -1) {
+			uid := m[1]
+			if uid == self || seen[uid] {
+				continue
+			}
+			seen[uid] = true
+			out = append(out, uid)
+		}
+	}
+	return out
+}
+
+// LinkURLs devolve as URLs de:
+// - links do dashboard
+// - links de panel
+// - data links (fieldConfig.defaults.links e overrides com id "links")
+func LinkURLs(dash map[string]any) []string {
+	var out []string
+
+	add := func(links any) {
+		list, _ := links.([]any)
+		for _, it := range list {
+			l, ok := it.(map[string]any)
+			if !ok {
+				continue
+			}
+			if u, _ := l["url"].(string); u != "" {
+				out = append(out, u)
+			}
+		}
+	}
+
+	add(dash["links"])
+	for _, p := range Panels(dash) {
+		add(p["links"])
+
+		fc, _ := p["fieldConfig"].(map[string]any)
+		if defaults, ok := fc["defaults"].(map[string]any); ok {
+			add(defaults["links"])
+		}
+		overrides, _ := fc["overrides"].([]any)
+		for _, o := range overrides {
+			om, _ := o.(map[string]any)
+			props, _ := om["properties"].([]any)
+			for _, pr := range props {
+				pm, _ := pr.(map[string]any)
+				if id, _ := pm["id"].(string); id == "links" {
+					add(pm["value"])
+				}
+			}
+		}
+	}
+	return out
+}
+
+// DependencyNode é um dashboard do grafo
+type DependencyNode struct {
+	UID     string   `json:"uid"`
+	Title   string   `json:"title,omitempty"`
+	Depth   int      `json:"depth"` // 0 = selecionado pelo usuário
+	Links   []string `json:"links"` // UIDs que este dashboard referencia
+	Found   bool     `json:"found"` // false = link quebrado na origem
+	Version int      `json:"version,omitempty"`
+	Hash    string   `json:"hash,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// DependencyGraph é o fecho dos links a partir dos dashboards selecionados
+type DependencyGraph struct {
+	Roots []string `json:"roots"`
+	// Nodes em ordem de BFS (raízes primeiro)
+	Nodes     []*DependencyNode `json:"nodes"`
+	Truncated bool              `json:"truncated"` // bateu em MaxDependencyDepth/MaxDependencyNodes
+}
+
+// DashboardFetcher lê um dashboard da origem; found=false p/ inexistente
+type DashboardFetcher func(ctx context.Context, uid string) (dash map[string]any, found bool, err error)
+
+// BuildDependencyGraph percorre os links (BFS) a partir das raízes
+func BuildDependencyGraph(ctx context.Context, fetch DashboardFetcher, roots []string) *DependencyGraph {
+	g := &DependencyGraph{Roots: roots, Nodes: []*DependencyNode{}}
+	seen := map[string]bool{}
+
+	queue := make([]*DependencyNode, 0, len(roots))
+	for _, uid := range roots {
+		if seen[uid] {
+			continue
+		}
+		seen[uid] = true
+		queue = append(queue, &DependencyNode{UID: uid})
+	}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		g.Nodes = append(g.Nodes, n)
+		n.Links = []string{}
+
+		dash, found, err := fetch(ctx, n.UID)
+		if err != nil {
+			n.Error = err.Error()
+			continue
+		}
+		if !found {
+			continue
+		}
+		n.Found = true
+		n.Title, _ = dash["title"].(string)
+		if v, ok := dash["version"].(float64); ok {
+			n.Version = int(v)
+		}
+		n.Hash, _ = ContentHash(dash)
+		if links := LinkedDashboardUIDs(dash); links != nil {
+			n.Links = links
+		}
+
+		for _, uid := range n.Links {
+			if seen[uid] {
+				continue
+			}
+			if n.Depth+1 > MaxDependencyDepth || len(seen) >= MaxDependencyNodes {
+				g.Truncated = true
+				continue
+			}
+			seen[uid] = true
+			queue = append(queue, &DependencyNode{UID: uid, Depth: n.Depth + 1})
+		}
+	}
+	return g
+}
+
+// Closure devolve os UIDs existentes na origem (raízes + linkados), na ordem do grafo
+func (g *DependencyGraph) Closure() []string {
+	out := make([]string, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		if n.Found {
+			out = append(out, n.UID)
+		}
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package transport
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// DefaultMessageTemplate é a mensagem de versão gravada no destino a cada import
+const DefaultMessageTemplate = `Transported from {{.SourceEnv}}{{with .SourceRef}}@{{.}}{{end}}{{with .SourceVersion}} version {{.}}{{end}}{{with .User}} by {{.}}{{end}}{{with .RequestedBy}} for {{.}}{{end}}{{with .JobID}} (job {{.}}){{end}}`
+
+// maxMessageLen é o tamanho da coluna message do histórico do Grafana
+const maxMessageLen = 255
+
+// MessageData são os campos disponíveis no template (IMPORT_MESSAGE_TEMPLATE)
+type MessageData struct {
+	SourceEnv     string // id do ambiente, "upload" ou "git"
+	SourceRef     string // ref do Git (só origem git)
+	SourceVersion int    // 0 = desconhecida
+	TargetEnv     string
+	UID           string
+	Title         string
+	User          string // usuário logado no Grafana
+	RequestedBy   string
+	JobID         string
+}
+
+// MessageTemplate gera a mensagem de versão dos dashboards importados
+type MessageTemplate struct {
+	tmpl *template.Template
+}
+
+// NewMessageTemplate compila o template; vazio ou inválido => DefaultMessageTemplate (inválido é logado)
+func NewMessageTemplate(text string) *MessageTemplate {
+	if strings.TrimSpace(text) != "" {
+		t, err := template.New("message").Parse(text)
+		if err == nil {
+			return &MessageTemplate{tmpl: t}
+		}
+		slog.Warn("IMPORT_MESSAGE_TEMPLATE inválido, usando o padrão", "error", err)
+	}
+	return &MessageTemplate{tmpl: template.Must(template.New("message").Parse(DefaultMessageTemplate))}
+}
+
+// Render gera a mensagem (uma linha, cortada no limite do Grafana); erro de execução => ""
+func (m *MessageTemplate) Render(data MessageData) string {
+	if m == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := m.tmpl.Execute(&buf, data); err != nil {
+		slog.Warn("import message template failed", "error", err)
+		return ""
+	}
+	msg := strings.Join(strings.Fields(buf.String()), " ")
+	if len(msg) > maxMessageLen {
+		cut := maxMessageLen - 3
+		for cut > 0 && !utf8.RuneStart(msg[cut]) {
+			cut--
+		}
+		msg = msg[:cut] + "..."
+	}
+	return msg
+}
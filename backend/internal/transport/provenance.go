@@ -0,0 +1,89 @@
+package transport
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const (
+	// ProvenanceKey é o campo do dashboard com a procedência do último transporte
+	ProvenanceKey = "transportProvenance"
+	// ProvenanceTagPrefix é o prefixo da tag de procedência (ex. "transported-from:dev")
+	ProvenanceTagPrefix = "transported-from:"
+)
+
+// Provenance registra de onde veio o dashboard importado
+type Provenance struct {
+	SourceEnv     string `json:"sourceEnv"` // id do ambiente, "upload" ou "git"
+	SourceRef     string `json:"sourceRef,omitempty"`
+	SourceUID     string `json:"sourceUid"`
+	SourceVersion int    `json:"sourceVersion,omitempty"`
+	// ContentHash é o ContentHash do dashboard transportado (ignora a própria procedência)
+	ContentHash   string    `json:"contentHash"`
+	TransportedAt time.Time `json:"transportedAt"`
+	TransportedBy string    `json:"transportedBy,omitempty"`
+	JobID         string    `json:"jobId,omitempty"`
+}
+
+// StampProvenance devolve uma cópia rasa do dashboard com a tag transported-from:<origem>
+// (substitui a de um transporte anterior) e o campo transportProvenance. O ContentHash
+// é calculado aqui, sobre o dashboard sem procedência.
+func StampProvenance(dash map[string]any, p Provenance) (map[string]any, error) {
+	hash, err := ContentHash(dash)
+	if err != nil {
+		return nil, err
+	}
+	p.ContentHash = hash
+
+	out := make(map[string]any, len(dash)+1)
+	for k, v := range dash {
+		out[k] = v
+	}
+	out["tags"] = append(withoutProvenanceTags(dash["tags"]), ProvenanceTagPrefix+p.SourceEnv)
+	out[ProvenanceKey] = p
+	return out, nil
+}
+
+// ReadProvenance lê o campo transportProvenance (false = dashboard não carimbado)
+func ReadProvenance(dash map[string]any) (*Provenance, bool) {
+	raw, ok := dash[ProvenanceKey]
+	if !ok || raw == nil {
+		return nil, false
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, false
+	}
+	var p Provenance
+	if err := json.Unmarshal(b, &p); err != nil || p.SourceEnv == "" {
+		return nil, false
+	}
+	return &p, true
+}
+
+// stripProvenance tira a procedência do dashboard já normalizado p/ o hash
+// (tags vazias e ausentes contam igual)
+func stripProvenance(norm map[string]any) {
+	delete(norm, ProvenanceKey)
+	if _, ok := norm["tags"]; !ok {
+		return
+	}
+	if tags := withoutProvenanceTags(norm["tags"]); len(tags) > 0 {
+		norm["tags"] = tags
+	} else {
+		delete(norm, "tags")
+	}
+}
+
+func withoutProvenanceTags(v any) []any {
+	list, _ := v.([]any)
+	out := make([]any, 0, len(list)+1)
+	for _, t := range list {
+		if s, ok := t.(string); ok && strings.HasPrefix(s, ProvenanceTagPrefix) {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
@@ -1,12 +1,30 @@
 package transport
 
+// SanitizeDashboard prepara o dashboard p/ POST /api/dashboards/db no destino.
+// Devolve uma cópia rasa; o original não é alterado.
 func SanitizeDashboard(dashboard map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(dashboard))
+	for k, v := range dashboard {
+		out[k] = v
+	}
+
 	// Campos que NUNCA devem ir para outro ambiente
-	delete(dashboard, "id")
-	delete(dashboard, "version")
+	// - id: conflita com folders/dashboards no destino
+	// - version: em import deve ser 0 (overwrite resolve)
+	// - uid: mantém (é a "identidade" que a gente transporta)
+	out["id"] = nil
+	out["version"] = 0
+
+	// metadados que às vezes vêm junto (export do Grafana / API full response)
+	delete(out, "meta")
+	delete(out, "folderId")
+	delete(out, "folderUid")
+	delete(out, "folderTitle")
 
-	// Opcional (recomendado):
-	delete(dashboard, "uid") // decide depois se mantém ou não
+	// "Export for sharing externally": não fazem parte do model do dashboard
+	delete(out, "__inputs")
+	delete(out, "__elements")
+	delete(out, "__requires")
 
-	return dashboard
+	return out
 }
@@ -0,0 +1,265 @@
+package transport
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	"dashboard-transporter/internal/grafana"
+)
+
+// limites p/ arquivos enviados (upload e conteúdo do ZIP)
+const (
+	MaxDashboardFileSize = 20 << 20  // 20 MiB por dashboard
+	MaxBundleSize        = 200 << 20 // 200 MiB descomprimidos somando o ZIP inteiro
+	MaxBundleEntries     = 5000
+)
+
+// ErrBundleTooLarge = o ZIP descomprimido passa do MaxBundleSize (rejeita o bundle inteiro)
+var ErrBundleTooLarge = fmt.Errorf("bundle too large (max %d MiB uncompressed)", MaxBundleSize>>20)
+
+// Grafana aceita uid com até 40 chars [a-zA-Z0-9-_]
+var reDashboardUID = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,40}$`)
+
+// ValidDashboardUID diz se o uid está no formato aceito pelo Grafana
+func ValidDashboardUID(uid string) bool {
+	return reDashboardUID.MatchString(uid)
+}
+
+// UploadedDashboard é um dashboard lido de arquivo (JSON solto ou de dentro do bundle)
+type UploadedDashboard struct {
+	Source    string // nome do arquivo / caminho no ZIP
+	Dashboard map[string]any
+	Err       error // preenchido quando o item é inválido (o resto segue)
+	// LibraryPanels do bundle de onde veio (compartilhado entre os itens do mesmo ZIP)
+	LibraryPanels map[string]*grafana.LibraryElement
+}
+
+// ID identifica o item no resultado: uid do dashboard ou, sem uid, o nome do arquivo
+func (u UploadedDashboard) ID() string {
+	if u.Dashboard != nil {
+		if uid, _ := u.Dashboard["uid"].(string); uid != "" {
+			return uid
+		}
+	}
+	return u.Source
+}
+
+// ParseDashboardJSON valida um JSON de dashboard. Aceita o model puro, o
+// "export for sharing externally" e a resposta completa da API ({dashboard, meta}).
+func ParseDashboardJSON(data []byte) (map[string]any, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid json: %w", err)
+	}
+
+	if inner, ok := raw["dashboard"].(map[string]any); ok {
+		if _, hasMeta := raw["meta"]; hasMeta {
+			raw = inner
+		}
+	}
+
+	title, _ := raw["title"].(string)
+	if strings.TrimSpace(title) == "" {
+		return nil, errors.New("not a dashboard: missing title")
+	}
+	_, hasPanels := raw["panels"]
+	_, hasRows := raw["rows"]
+	if !hasPanels && !hasRows {
+		return nil, errors.New("not a dashboard: missing panels")
+	}
+
+	if v, ok := raw["uid"]; ok && v != nil {
+		uid, isString := v.(string)
+		if !isString || (uid != "" && !ValidDashboardUID(uid)) {
+			return nil, fmt.Errorf("invalid uid %v", v)
+		}
+	}
+
+	return raw, nil
+}
+
+// IsZip diz se o conteúdo começa com a assinatura de um ZIP
+func IsZip(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("PK\x03\x04"))
+}
+
+// ReadBundle lê um bundle gerado pelo export (manifest.json + dashboards/...).
+// Confere o sha256 de cada dashboard listado no manifest; ZIP sem manifest
+// também é aceito (todo *.json vira candidato a dashboard).
+func ReadBundle(data []byte) (*BundleManifest, []UploadedDashboard, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid zip: %w", err)
+	}
+	if len(zr.File) > MaxBundleEntries {
+		return nil, nil, fmt.Errorf("bundle has too many entries (%d > %d)", len(zr.File), MaxBundleEntries)
+	}
+
+	// o header pode mentir: o budget também conta o que foi lido de verdade
+	var declared uint64
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[path.Clean(f.Name)] = f
+		declared += f.UncompressedSize64
+	}
+	if declared > MaxBundleSize {
+		return nil, nil, ErrBundleTooLarge
+	}
+	budget := &bundleBudget{remaining: MaxBundleSize}
+
+	var manifest *BundleManifest
+	if f, ok := files[BundleManifestName]; ok {
+		b, err := budget.read(f)
+		if errors.Is(err, ErrBundleTooLarge) {
+			return nil, nil, err
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("manifest: %w", err)
+		}
+		manifest = &BundleManifest{}
+		if err := json.Unmarshal(b, manifest); err != nil {
+			return nil, nil, fmt.Errorf("manifest: invalid json: %w", err)
+		}
+	}
+
+	var out []UploadedDashboard
+
+	if manifest != nil {
+		for _, d := range manifest.Dashboards {
+			item := UploadedDashboard{Source: d.Path}
+			f, ok := files[path.Clean(d.Path)]
+			if !ok {
+				item.Err = errors.New("listed in manifest but missing from bundle")
+				out = append(out, item)
+				continue
+			}
+			b, err := budget.read(f)
+			if errors.Is(err, ErrBundleTooLarge) {
+				return nil, nil, err
+			}
+			if err != nil {
+				item.Err = err
+				out = append(out, item)
+				continue
+			}
+			if d.SHA256 != "" {
+				sum := sha256.Sum256(b)
+				if hex.EncodeToString(sum[:]) != d.SHA256 {
+					item.Err = errors.New("checksum mismatch")
+					out = append(out, item)
+					continue
+				}
+			}
+			item.Dashboard, item.Err = ParseDashboardJSON(b)
+			out = append(out, item)
+		}
+
+		libs, err := bundleLibraryPanels(manifest, files, budget)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range out {
+			out[i].LibraryPanels = libs
+		}
+		return manifest, out, nil
+	}
+
+	for _, f := range zr.File {
+		name := path.Clean(f.Name)
+		if f.FileInfo().IsDir() || !strings.HasSuffix(strings.ToLower(name), ".json") {
+			continue
+		}
+		item := UploadedDashboard{Source: name}
+		b, err := budget.read(f)
+		if errors.Is(err, ErrBundleTooLarge) {
+			return nil, nil, err
+		}
+		if err != nil {
+			item.Err = err
+		} else {
+			item.Dashboard, item.Err = ParseDashboardJSON(b)
+		}
+		out = append(out, item)
+	}
+	return nil, out, nil
+}
+
+// bundleBudget limita o total descomprimido lido de um ZIP (zip bomb)
+type bundleBudget struct {
+	remaining int64
+}
+
+func (b *bundleBudget) read(f *zip.File) ([]byte, error) {
+	data, err := readZipFile(f, b.remaining)
+	if err != nil {
+		return nil, err
+	}
+	b.remaining -= int64(len(data))
+	return data, nil
+}
+
+// readZipFile lê uma entrada com no máximo MaxDashboardFileSize e o que resta do budget
+func readZipFile(f *zip.File, remaining int64) ([]byte, error) {
+	if f.UncompressedSize64 > MaxDashboardFileSize {
+		return nil, fmt.Errorf("%s: file too large", f.Name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	// não confia no header do ZIP: limita a leitura de verdade
+	limit := min(int64(MaxDashboardFileSize), remaining)
+	b, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > limit {
+		if limit < MaxDashboardFileSize {
+			return nil, ErrBundleTooLarge
+		}
+		return nil, fmt.Errorf("%s: file too large", f.Name)
+	}
+	return b, nil
+}
+
+// bundleLibraryPanels lê library-panels/*.json listados no manifest (itens inválidos são ignorados:
+// o import acusa o library panel como não encontrado). Só estourar o budget vira erro.
+func bundleLibraryPanels(manifest *BundleManifest, files map[string]*zip.File, budget *bundleBudget) (map[string]*grafana.LibraryElement, error) {
+	out := map[string]*grafana.LibraryElement{}
+	for _, lp := range manifest.LibraryPanels {
+		f, ok := files[path.Clean(lp.Path)]
+		if !ok {
+			continue
+		}
+		b, err := budget.read(f)
+		if errors.Is(err, ErrBundleTooLarge) {
+			return nil, err
+		}
+		if err != nil {
+			continue
+		}
+		if lp.SHA256 != "" {
+			sum := sha256.Sum256(b)
+			if hex.EncodeToString(sum[:]) != lp.SHA256 {
+				continue
+			}
+		}
+		var el grafana.LibraryElement
+		if err := json.Unmarshal(b, &el); err != nil || el.UID == "" || el.Model == nil {
+			continue
+		}
+		out[el.UID] = &el
+	}
+	return out, nil
+}
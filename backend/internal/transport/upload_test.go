@@ -0,0 +1,157 @@
+package transport
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const dashJSON = `{"uid":"abc","title":"A","panels":[]}`
+
+type zipEntry struct {
+	name string
+	data []byte
+}
+
+func buildZip(t *testing.T, entries ...zipEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		w, err := zw.Create(e.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(e.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func manifestEntry(t *testing.T, dashboards ...BundleDashboard) zipEntry {
+	t.Helper()
+	b, err := json.Marshal(BundleManifest{FormatVersion: 1, Dashboards: dashboards})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zipEntry{BundleManifestName, b}
+}
+
+func sha(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestParseDashboardJSON(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"model", dashJSON, false},
+		{"api response", `{"dashboard":` + dashJSON + `,"meta":{"id":1}}`, false},
+		{"rows", `{"title":"A","rows":[]}`, false},
+		{"no title", `{"panels":[]}`, true},
+		{"no panels", `{"title":"A"}`, true},
+		{"invalid json", `{`, true},
+		{"uid not a string", `{"uid":1,"title":"A","panels":[]}`, true},
+		{"uid with slash", `{"uid":"../x","title":"A","panels":[]}`, true},
+		{"uid too long", `{"uid":"` + strings.Repeat("a", 41) + `","title":"A","panels":[]}`, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseDashboardJSON([]byte(c.data))
+			if (err != nil) != c.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadBundleChecksum(t *testing.T) {
+	good := []byte(dashJSON)
+	data := buildZip(t,
+		manifestEntry(t,
+			BundleDashboard{UID: "abc", Path: "dashboards/a.json", SHA256: sha(good)},
+			BundleDashboard{UID: "bad", Path: "dashboards/b.json", SHA256: sha([]byte("other"))},
+			BundleDashboard{UID: "gone", Path: "dashboards/c.json"},
+		),
+		zipEntry{"dashboards/a.json", good},
+		zipEntry{"dashboards/b.json", good},
+	)
+
+	manifest, items, err := ReadBundle(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest == nil || len(items) != 3 {
+		t.Fatalf("manifest = %v, items = %+v", manifest, items)
+	}
+	if items[0].Err != nil || items[0].ID() != "abc" {
+		t.Errorf("valid item: %+v", items[0])
+	}
+	if items[1].Err == nil || !strings.Contains(items[1].Err.Error(), "checksum mismatch") || items[1].Dashboard != nil {
+		t.Errorf("bad checksum accepted: %+v", items[1])
+	}
+	if items[2].Err == nil {
+		t.Errorf("missing file accepted: %+v", items[2])
+	}
+}
+
+func TestReadBundleOversizedEntry(t *testing.T) {
+	big := bytes.Repeat([]byte(" "), MaxDashboardFileSize+1)
+	data := buildZip(t,
+		zipEntry{"a.json", []byte(dashJSON)},
+		zipEntry{"big.json", big},
+	)
+
+	_, items, err := ReadBundle(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("items = %+v", items)
+	}
+	if items[0].Err != nil {
+		t.Errorf("small entry rejected: %v", items[0].Err)
+	}
+	if items[1].Err == nil || !strings.Contains(items[1].Err.Error(), "file too large") {
+		t.Errorf("oversized entry accepted: %+v", items[1].Err)
+	}
+}
+
+func TestReadBundleTooLarge(t *testing.T) {
+	// cada entrada cabe no limite por arquivo, a soma passa do MaxBundleSize
+	chunk := bytes.Repeat([]byte(" "), MaxDashboardFileSize)
+	var entries []zipEntry
+	for i := 0; i <= MaxBundleSize/MaxDashboardFileSize; i++ {
+		entries = append(entries, zipEntry{fmt.Sprintf("d%d.json", i), chunk})
+	}
+
+	_, _, err := ReadBundle(buildZip(t, entries...))
+	if !errors.Is(err, ErrBundleTooLarge) {
+		t.Fatalf("err = %v, want ErrBundleTooLarge", err)
+	}
+}
+
+func TestReadBundleTooManyEntries(t *testing.T) {
+	entries := make([]zipEntry, MaxBundleEntries+1)
+	for i := range entries {
+		entries[i] = zipEntry{fmt.Sprintf("d%d.json", i), nil}
+	}
+
+	_, _, err := ReadBundle(buildZip(t, entries...))
+	if err == nil || !strings.Contains(err.Error(), "too many entries") {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package transport
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"dashboard-transporter/internal/grafana"
+)
+
+// severidade dos problemas encontrados na validação
+const (
+	SeverityError   = "error"   // bloqueia o import
+	SeverityWarning = "warning" // importa, mas anota o resultado
+)
+
+// Issue é um problema encontrado no dashboard antes do import
+type Issue struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	// Path é o caminho JSON do problema no dashboard (ex. "$.panels[2].options.content")
+	Path string `json:"path,omitempty"`
+}
+
+// TargetInfo é o que a validação precisa saber do ambiente de destino.
+// Campos nil/vazios desligam o check correspondente (ex. /api/plugins sem permissão).
+type TargetInfo struct {
+	DataSources []grafana.DataSource
+	// PanelPlugins são os ids de panel instalados (nil = não checa)
+	PanelPlugins map[string]bool
+	// GrafanaVersion do /api/health ("" = não checa schemaVersion)
+	GrafanaVersion string
+	// ForeignHosts são hostnames de outros ambientes que não devem aparecer em links
+	ForeignHosts []string
+}
+
+// panels que não são plugins (não aparecem em /api/plugins)
+var nonPluginPanels = map[string]bool{"row": true}
+
+// schemaVersion máximo gravado por cada versão do Grafana (major.minor), aproximado
+// pelos changelogs. Versões acima da última conhecida não são checadas.
+var maxSchemaVersions = []struct {
+	major, minor, schema int
+}{
+	{7, 0, 25}, {7, 1, 26}, {7, 4, 27},
+	{8, 0, 30}, {8, 2, 31}, {8, 3, 33}, {8, 4, 35},
+	{9, 0, 36}, {9, 1, 37}, {9, 4, 38},
+	{10, 0, 38}, {10, 3, 39},
+	{11, 0, 39}, {11, 3, 40},
+	{12, 0, 41},
+}
+
+// ValidateDashboard checa o dashboard contra o destino:
+// título vazio, datasources não resolvidos, panels sem plugin, schemaVersion
+// mais novo que o destino e links com hostnames de outros ambientes.
+func ValidateDashboard(dash map[string]any, target TargetInfo) []Issue {
+	var issues []Issue
+	add := func(sev, code, format string, args ...any) {
+		issues = append(issues, Issue{Severity: sev, Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if title, _ := dash["title"].(string); strings.TrimSpace(title) == "" {
+		add(SeverityError, "empty_title", "dashboard title is empty")
+	}
+
+	if target.DataSources != nil {
+		byUID := map[string]bool{}
+		byName := map[string]bool{}
+		for _, ds := range target.DataSources {
+			byUID[ds.UID] = true
+			byName[ds.Name] = true
+		}
+		for _, ref := range DatasourceRefs(dash) {
+			switch {
+			case ref.UID != "" && !byUID[ref.UID] && !byName[ref.UID]:
+				add(SeverityError, "datasource_unresolved", "datasource %s (%s) not found in target", ref.UID, firstNonEmpty(ref.Type, "unknown type"))
+			case ref.UID == "" && ref.Name != "" && !byName[ref.Name]:
+				add(SeverityError, "datasource_unresolved", "datasource %q not found in target", ref.Name)
+			}
+		}
+	}
+
+	if target.PanelPlugins != nil {
+		seen := map[string]bool{}
+		for _, p := range Panels(dash) {
+			typ, _ := p["type"].(string)
+			if typ == "" || nonPluginPanels[typ] || seen[typ] || target.PanelPlugins[typ] {
+				continue
+			}
+			seen[typ] = true
+			add(SeverityError, "panel_plugin_missing", "panel plugin %q is not installed in target", typ)
+		}
+	}
+
+	if max, ok := MaxSchemaVersion(target.GrafanaVersion); ok {
+		if v, _ := dash["schemaVersion"].(float64); int(v) > max {
+			add(SeverityError, "schema_too_new", "schemaVersion %d is newer than target Grafana %s supports (%d)", int(v), target.GrafanaVersion, max)
+		}
+	}
+
+	if len(target.ForeignHosts) > 0 {
+		seen := map[string]bool{}
+		for _, raw := range LinkURLs(dash) {
+			u, err := url.Parse(raw)
+			if err != nil || u.Host == "" {
+				continue
+			}
+			host := strings.ToLower(u.Hostname())
+			for _, h := range target.ForeignHosts {
+				if host == h && !seen[raw] {
+					seen[raw] = true
+					add(SeverityWarning, "foreign_host_link", "link points to another environment: %s", raw)
+				}
+			}
+		}
+	}
+
+	return issues
+}
+
+// HasErrors indica se algum problema bloqueia o import
+func HasErrors(issues []Issue) bool {
+	for _, is := range issues {
+		if is.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxSchemaVersion devolve o schemaVersion máximo da versão do Grafana ("10.4.2", "11.0.0-preview")
+func MaxSchemaVersion(version string) (int, bool) {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return 0, false
+	}
+	major, err1 := strconv.Atoi(parts[0])
+	minor, err2 := strconv.Atoi(strings.TrimLeft(parts[1], "v"))
+	if err1 != nil || err2 != nil {
+		return 0, false
+	}
+
+	last := maxSchemaVersions[len(maxSchemaVersions)-1]
+	if major > last.major || (major == last.major && minor > last.minor) {
+		return 0, false // mais novo que a tabela: não dá p/ afirmar nada
+	}
+
+	best := -1
+	for _, v := range maxSchemaVersions {
+		if v.major < major || (v.major == major && v.minor <= minor) {
+			best = v.schema
+		}
+	}
+	return best, best >= 0
+}
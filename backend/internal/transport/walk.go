@@ -0,0 +1,182 @@
+package transport
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// DeepCopy clona o dashboard (round-trip JSON) p/ transformar sem mexer no original
+func DeepCopy(dash map[string]any) map[string]any {
+	b, err := json.Marshal(dash)
+	if err != nil {
+		return nil
+	}
+	var out map[string]any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// DashboardVersion devolve o campo version do dashboard (0 = ausente)
+func DashboardVersion(dash map[string]any) int {
+	return intValue(dash["version"])
+}
+
+// Panels devolve todos os panels do dashboard, achatando:
+// - panels de nível raiz
+// - panels dentro de rows colapsadas (row.panels)
+// - schema antigo (dashboard.rows[].panels)
+func Panels(dash map[string]any) []map[string]any {
+	var out []map[string]any
+
+	var visit func(list []any)
+	visit = func(list []any) {
+		for _, it := range list {
+			p, ok := it.(map[string]any)
+			if !ok {
+				continue
+			}
+			out = append(out, p)
+			if nested, ok := p["panels"].([]any); ok {
+				visit(nested)
+			}
+		}
+	}
+
+	if list, ok := dash["panels"].([]any); ok {
+		visit(list)
+	}
+	if rows, ok := dash["rows"].([]any); ok {
+		for _, r := range rows {
+			if row, ok := r.(map[string]any); ok {
+				if list, ok := row["panels"].([]any); ok {
+					visit(list)
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+// TemplateVariables devolve templating.list
+func TemplateVariables(dash map[string]any) []map[string]any {
+	return objectList(dash, "templating")
+}
+
+// Annotations devolve annotations.list
+func Annotations(dash map[string]any) []map[string]any {
+	return objectList(dash, "annotations")
+}
+
+func objectList(dash map[string]any, key string) []map[string]any {
+	holder, ok := dash[key].(map[string]any)
+	if !ok {
+		return nil
+	}
+	list, ok := holder["list"].([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]map[string]any, 0, len(list))
+	for _, it := range list {
+		if m, ok := it.(map[string]any); ok {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// DatasourceHolders devolve todo objeto do dashboard que pode ter um campo "datasource":
+// panels, targets dos panels, variáveis (exceto type=datasource) e annotations.
+func DatasourceHolders(dash map[string]any) []map[string]any {
+	var out []map[string]any
+
+	for _, p := range Panels(dash) {
+		out = append(out, p)
+		if targets, ok := p["targets"].([]any); ok {
+			for _, t := range targets {
+				if m, ok := t.(map[string]any); ok {
+					out = append(out, m)
+				}
+			}
+		}
+	}
+
+	for _, v := range TemplateVariables(dash) {
+		if v["type"] == "datasource" {
+			continue
+		}
+		out = append(out, v)
+	}
+
+	out = append(out, Annotations(dash)...)
+	return out
+}
+
+// DatasourceRef é uma referência a datasource encontrada no dashboard
+type DatasourceRef struct {
+	UID  string `json:"uid,omitempty"`
+	Type string `json:"type,omitempty"`
+	// Name vem preenchido quando a referência é legada (string com o nome)
+	Name string `json:"name,omitempty"`
+}
+
+// DatasourceRefs devolve as referências de datasource do dashboard (sem duplicados),
+// ignorando variáveis (${ds}) e datasources embutidos (-- Grafana --, -- Mixed --...)
+func DatasourceRefs(dash map[string]any) []DatasourceRef {
+	var out []DatasourceRef
+	seen := map[DatasourceRef]bool{}
+
+	for _, holder := range DatasourceHolders(dash) {
+		var ref DatasourceRef
+		switch v := holder["datasource"].(type) {
+		case string:
+			ref.Name = v
+		case map[string]any:
+			ref.UID, _ = v["uid"].(string)
+			ref.Type, _ = v["type"].(string)
+		default:
+			continue
+		}
+
+		key := ref.UID + ref.Name
+		if key == "" || strings.HasPrefix(key, "$") || builtinDatasources[key] {
+			continue
+		}
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		out = append(out, ref)
+	}
+	return out
+}
+
+// LibraryPanelRef é um panel que aponta p/ um library panel (libraryPanel.uid)
+type LibraryPanelRef struct {
+	UID  string `json:"uid"`
+	Name string `json:"name,omitempty"`
+}
+
+// LibraryPanelRefs devolve os library panels usados no dashboard (sem duplicados)
+func LibraryPanelRefs(dash map[string]any) []LibraryPanelRef {
+	var out []LibraryPanelRef
+	seen := map[string]bool{}
+
+	for _, p := range Panels(dash) {
+		lp, ok := p["libraryPanel"].(map[string]any)
+		if !ok {
+			continue
+		}
+		uid, _ := lp["uid"].(string)
+		if uid == "" || seen[uid] {
+			continue
+		}
+		seen[uid] = true
+		name, _ := lp["name"].(string)
+		out = append(out, LibraryPanelRef{UID: uid, Name: name})
+	}
+	return out
+}
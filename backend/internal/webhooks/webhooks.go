@@ -0,0 +1,328 @@
+// Package webhooks entrega eventos de transporte (assinados com HMAC) para
+// ferramentas externas (chat, ITSM), com retry/backoff e log de entregas.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"dashboard-transporter/internal/config"
+	"dashboard-transporter/internal/grafana"
+	"dashboard-transporter/internal/logging"
+)
+
+// eventos de transporte
+const (
+	EventStarted         = "transport.started"
+	EventSucceeded       = "transport.succeeded"        // todos os dashboards ok/warning
+	EventPartiallyFailed = "transport.partially_failed" // parte deu erro
+	EventFailed          = "transport.failed"           // todos deram erro
+	EventRolledBack      = "transport.rolled_back"      // versões anteriores restauradas no próprio ambiente, todas ok
+)
+
+// headers enviados em cada entrega
+const (
+	HeaderEvent     = "X-Transporter-Event"
+	HeaderDelivery  = "X-Transporter-Delivery"
+	HeaderSignature = "X-Transporter-Signature" // "sha256=<hex>" do body (HMAC com o secret)
+)
+
+// backoff entre tentativas: 1s, 2s, 4s... até maxBackoff
+const (
+	baseBackoff = time.Second
+	maxBackoff  = time.Minute
+)
+
+// Dashboard é o resultado de um dashboard no evento
+type Dashboard struct {
+	SourceUID string `json:"sourceUid"`
+	TargetUID string `json:"targetUid,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Message   string `json:"message,omitempty"`
+	URL       string `json:"url,omitempty"` // link do dashboard no ambiente de destino
+}
+
+// Summary conta os status do transporte
+type Summary struct {
+	Total   int `json:"total"`
+	OK      int `json:"ok"`
+	Warning int `json:"warning"`
+	Error   int `json:"error"`
+}
+
+// Event é o payload JSON enviado
+type Event struct {
+	ID          string      `json:"id"`
+	Type        string      `json:"event"`
+	Timestamp   time.Time   `json:"timestamp"`
+	JobID       string      `json:"jobId,omitempty"`
+	User        string      `json:"user,omitempty"`
+	RequestedBy string      `json:"requestedBy,omitempty"`
+	SourceEnv   string      `json:"sourceEnv"`
+	SourceRef   string      `json:"sourceRef,omitempty"`
+	TargetEnv   string      `json:"targetEnv"`
+	TargetURL   string      `json:"targetUrl,omitempty"`
+	UIDs        []string    `json:"uids"`
+	Summary     *Summary    `json:"summary,omitempty"`
+	Dashboards  []Dashboard `json:"dashboards,omitempty"`
+}
+
+// Attempt é uma tentativa de entrega
+type Attempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+}
+
+// Delivery é uma entrada do log de entregas
+type Delivery struct {
+	ID        string    `json:"id"`
+	Webhook   string    `json:"webhook"`
+	Event     string    `json:"event"`
+	EventID   string    `json:"eventId"`
+	JobID     string    `json:"jobId,omitempty"`
+	Status    string    `json:"status"` // pending | delivered | failed
+	CreatedAt time.Time `json:"createdAt"`
+	Attempts  []Attempt `json:"attempts"`
+}
+
+// Dispatcher entrega eventos em background. Um Dispatcher nil é válido (no-op).
+type Dispatcher struct {
+	endpoints   []config.WebhookEndpoint
+	maxAttempts int
+	backoff     time.Duration // espera antes do 1º retry (dobra a cada tentativa)
+	hc          *http.Client
+
+	wg   sync.WaitGroup
+	stop chan struct{}
+
+	mu      sync.Mutex
+	log     []*Delivery // mais antiga primeiro
+	logSize int
+}
+
+// New devolve nil quando não há endpoints configurados
+func New(cfg config.WebhooksConfig) *Dispatcher {
+	if len(cfg.Endpoints) == 0 {
+		return nil
+	}
+	return &Dispatcher{
+		endpoints:   cfg.Endpoints,
+		maxAttempts: cfg.MaxAttempts,
+		backoff:     baseBackoff,
+		hc:          &http.Client{Timeout: cfg.Timeout},
+		stop:        make(chan struct{}),
+		logSize:     cfg.LogSize,
+	}
+}
+
+// Emit completa id/timestamp/job e agenda a entrega p/ cada endpoint inscrito no evento
+func (d *Dispatcher) Emit(ctx context.Context, ev Event) {
+	if d == nil {
+		return
+	}
+	ev.ID = logging.NewID()
+	ev.Timestamp = time.Now().UTC()
+	if ev.JobID == "" {
+		ev.JobID = logging.JobID(ctx)
+	}
+
+	body, err := json.Marshal(ev)
+	if err != nil {
+		slog.ErrorContext(ctx, "webhook event not encoded", "event", ev.Type, "error", err)
+		return
+	}
+
+	for _, ep := range d.endpoints {
+		if !subscribed(ep, ev.Type) {
+			continue
+		}
+		del := &Delivery{
+			ID:        logging.NewID(),
+			Webhook:   ep.Name,
+			Event:     ev.Type,
+			EventID:   ev.ID,
+			JobID:     ev.JobID,
+			Status:    "pending",
+			CreatedAt: ev.Timestamp,
+			Attempts:  []Attempt{},
+		}
+		d.record(del)
+
+		d.wg.Add(1)
+		go func(ep config.WebhookEndpoint) {
+			defer d.wg.Done()
+			d.deliver(context.WithoutCancel(ctx), ep, del, body)
+		}(ep)
+	}
+}
+
+// Close para os retries pendentes e espera as entregas em andamento (até ctx expirar)
+func (d *Dispatcher) Close(ctx context.Context) {
+	if d == nil {
+		return
+	}
+	close(d.stop)
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// Deliveries devolve o log (mais recente primeiro); event/webhook vazios = sem filtro
+func (d *Dispatcher) Deliveries(event, webhook string) []Delivery {
+	out := []Delivery{}
+	if d == nil {
+		return out
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i := len(d.log) - 1; i >= 0; i-- {
+		del := d.log[i]
+		if (event != "" && del.Event != event) || (webhook != "" && del.Webhook != webhook) {
+			continue
+		}
+		cp := *del
+		cp.Attempts = append([]Attempt{}, del.Attempts...)
+		out = append(out, cp)
+	}
+	return out
+}
+
+// Endpoints devolve os destinos configurados (sem secret)
+func (d *Dispatcher) Endpoints() []config.WebhookEndpoint {
+	if d == nil {
+		return []config.WebhookEndpoint{}
+	}
+	out := make([]config.WebhookEndpoint, 0, len(d.endpoints))
+	for _, ep := range d.endpoints {
+		ep.Secret = ""
+		out = append(out, ep)
+	}
+	return out
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, ep config.WebhookEndpoint, del *Delivery, body []byte) {
+	backoff := d.backoff
+	for attempt := 1; ; attempt++ {
+		a, retry := d.send(ctx, ep, del, body)
+		d.update(del, a, "")
+
+		if a.Error == "" {
+			d.update(del, Attempt{}, "delivered")
+			slog.InfoContext(ctx, "webhook delivered", "webhook", ep.Name, "event", del.Event, "attempts", attempt)
+			return
+		}
+		if !retry || attempt >= d.maxAttempts {
+			d.update(del, Attempt{}, "failed")
+			slog.WarnContext(ctx, "webhook delivery failed", "webhook", ep.Name, "event", del.Event, "attempts", attempt, "error", a.Error)
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-d.stop:
+			d.update(del, Attempt{}, "failed")
+			slog.WarnContext(ctx, "webhook retry aborted by shutdown", "webhook", ep.Name, "event", del.Event, "attempts", attempt)
+			return
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// send faz uma tentativa; retry=false p/ erros definitivos (4xx exceto 408/429)
+func (d *Dispatcher) send(ctx context.Context, ep config.WebhookEndpoint, del *Delivery, body []byte) (Attempt, bool) {
+	a := Attempt{At: time.Now().UTC()}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(body))
+	if err != nil {
+		a.Error = err.Error()
+		return a, false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "dashboard-transporter")
+	req.Header.Set(HeaderEvent, del.Event)
+	req.Header.Set(HeaderDelivery, del.ID)
+	if ep.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(ep.Secret, body))
+	}
+
+	resp, err := d.hc.Do(req)
+	if err != nil {
+		a.Error = logging.Redact(err.Error())
+		a.DurationMs = time.Since(a.At).Milliseconds()
+		return a, true
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	a.StatusCode = resp.StatusCode
+	a.DurationMs = time.Since(a.At).Milliseconds()
+	if resp.StatusCode < 300 {
+		return a, false
+	}
+	a.Error = fmt.Sprintf("http %d: %s", resp.StatusCode, grafana.ErrorMessage(respBody))
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout
+	return a, retry
+}
+
+// Sign devolve o valor do header de assinatura: "sha256=" + hex(HMAC-SHA256(secret, body))
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func subscribed(ep config.WebhookEndpoint, event string) bool {
+	if len(ep.Events) == 0 {
+		return true
+	}
+	for _, e := range ep.Events {
+		if e == event || e == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Dispatcher) record(del *Delivery) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.log = append(d.log, del)
+	if len(d.log) > d.logSize {
+		d.log = d.log[len(d.log)-d.logSize:]
+	}
+}
+
+// update acrescenta a tentativa (se At preenchido) e/ou muda o status
+func (d *Dispatcher) update(del *Delivery, a Attempt, status string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !a.At.IsZero() {
+		del.Attempts = append(del.Attempts, a)
+	}
+	if status != "" {
+		del.Status = status
+	}
+}
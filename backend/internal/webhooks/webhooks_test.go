@@ -0,0 +1,261 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"dashboard-transporter/internal/config"
+)
+
+// receiver responde com os status da fila (o último se repete) e guarda cada request
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	calls    []call
+}
+
+type call struct {
+	at     time.Time
+	header http.Header
+	body   []byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.calls = append(rc.calls, call{at: time.Now(), header: r.Header.Clone(), body: body})
+	status := rc.statuses[0]
+	if len(rc.statuses) > 1 {
+		rc.statuses = rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(`{"message":"nope"}`))
+}
+
+func (rc *receiver) got() []call {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return append([]call{}, rc.calls...)
+}
+
+func newDispatcher(t *testing.T, rc *receiver, ep config.WebhookEndpoint, maxAttempts int) *Dispatcher {
+	t.Helper()
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	ep.URL = srv.URL
+	if ep.Name == "" {
+		ep.Name = "itsm"
+	}
+	d := New(config.WebhooksConfig{
+		Endpoints:   []config.WebhookEndpoint{ep},
+		MaxAttempts: maxAttempts,
+		Timeout:     time.Second,
+		LogSize:     10,
+	})
+	d.backoff = 10 * time.Millisecond
+	return d
+}
+
+// wait espera as entregas terminarem
+func wait(t *testing.T, d *Dispatcher) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("deliveries did not finish")
+	}
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"transport.started"}`)
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if got := Sign("s3cret", body); got != want {
+		t.Errorf("Sign = %q, want %q", got, want)
+	}
+	if Sign("other", body) == want {
+		t.Error("signature does not depend on the secret")
+	}
+}
+
+func TestDeliverHeadersAndSignature(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusOK}}
+	d := newDispatcher(t, rc, config.WebhookEndpoint{Secret: "s3cret"}, 3)
+
+	d.Emit(context.Background(), Event{Type: EventSucceeded, SourceEnv: "hml", TargetEnv: "prd", UIDs: []string{"abc"}})
+	wait(t, d)
+
+	calls := rc.got()
+	if len(calls) != 1 {
+		t.Fatalf("calls = %d", len(calls))
+	}
+	c := calls[0]
+	if got := c.header.Get(HeaderSignature); got != Sign("s3cret", c.body) {
+		t.Errorf("signature = %q, want %q", got, Sign("s3cret", c.body))
+	}
+	if c.header.Get(HeaderEvent) != EventSucceeded || c.header.Get(HeaderDelivery) == "" {
+		t.Errorf("headers = %v", c.header)
+	}
+	var ev Event
+	if err := json.Unmarshal(c.body, &ev); err != nil || ev.ID == "" || ev.Type != EventSucceeded || ev.TargetEnv != "prd" {
+		t.Errorf("body = %s (%v)", c.body, err)
+	}
+
+	dels := d.Deliveries("", "")
+	if len(dels) != 1 || dels[0].Status != "delivered" || len(dels[0].Attempts) != 1 || dels[0].Attempts[0].StatusCode != http.StatusOK {
+		t.Errorf("deliveries = %+v", dels)
+	}
+}
+
+func TestDeliverUnsignedWithoutSecret(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusNoContent}}
+	d := newDispatcher(t, rc, config.WebhookEndpoint{}, 3)
+
+	d.Emit(context.Background(), Event{Type: EventStarted})
+	wait(t, d)
+
+	if calls := rc.got(); len(calls) != 1 || calls[0].header.Get(HeaderSignature) != "" {
+		t.Errorf("calls = %+v", calls)
+	}
+}
+
+func TestDeliverRetries(t *testing.T) {
+	cases := []struct {
+		name     string
+		statuses []int
+		attempts int
+		status   string
+	}{
+		{"5xx then ok", []int{500, 502, 200}, 3, "delivered"},
+		{"408 is retried", []int{408, 200}, 2, "delivered"},
+		{"429 is retried", []int{429, 200}, 2, "delivered"},
+		{"other 4xx is final", []int{400, 200}, 1, "failed"},
+		{"404 is final", []int{404}, 1, "failed"},
+		{"gives up after max attempts", []int{503}, 4, "failed"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rc := &receiver{statuses: c.statuses}
+			d := newDispatcher(t, rc, config.WebhookEndpoint{}, 4)
+
+			d.Emit(context.Background(), Event{Type: EventFailed})
+			wait(t, d)
+
+			if got := len(rc.got()); got != c.attempts {
+				t.Errorf("requests = %d, want %d", got, c.attempts)
+			}
+			dels := d.Deliveries("", "")
+			if len(dels) != 1 || dels[0].Status != c.status || len(dels[0].Attempts) != c.attempts {
+				t.Fatalf("deliveries = %+v", dels)
+			}
+			last := dels[0].Attempts[c.attempts-1]
+			if c.status == "failed" && (last.Error == "" || last.StatusCode != c.statuses[min(c.attempts, len(c.statuses))-1]) {
+				t.Errorf("last attempt = %+v", last)
+			}
+		})
+	}
+}
+
+func TestDeliverBackoff(t *testing.T) {
+	rc := &receiver{statuses: []int{500}}
+	d := newDispatcher(t, rc, config.WebhookEndpoint{}, 4)
+	d.backoff = 40 * time.Millisecond
+
+	d.Emit(context.Background(), Event{Type: EventFailed})
+	wait(t, d)
+
+	calls := rc.got()
+	if len(calls) != 4 {
+		t.Fatalf("calls = %d", len(calls))
+	}
+	// 40ms, 80ms, 160ms
+	for i, want := range []time.Duration{40, 80, 160} {
+		if gap := calls[i+1].at.Sub(calls[i].at); gap < want*time.Millisecond {
+			t.Errorf("gap %d = %s, want >= %dms", i+1, gap, want)
+		}
+	}
+}
+
+func TestCloseStopsRetries(t *testing.T) {
+	rc := &receiver{statuses: []int{500}}
+	d := newDispatcher(t, rc, config.WebhookEndpoint{}, 10)
+	d.backoff = time.Hour
+
+	d.Emit(context.Background(), Event{Type: EventFailed})
+	for len(d.Deliveries("", "")[0].Attempts) == 0 {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	start := time.Now()
+	d.Close(ctx)
+	if time.Since(start) > time.Second {
+		t.Error("Close waited for the backoff")
+	}
+
+	dels := d.Deliveries("", "")
+	if dels[0].Status != "failed" || len(dels[0].Attempts) != 1 || len(rc.got()) != 1 {
+		t.Errorf("deliveries = %+v, calls = %d", dels, len(rc.got()))
+	}
+}
+
+func TestDeliveriesLogAndFilters(t *testing.T) {
+	rc := &receiver{statuses: []int{200}}
+	d := newDispatcher(t, rc, config.WebhookEndpoint{Events: []string{EventSucceeded, EventRolledBack}}, 1)
+	d.logSize = 3
+
+	for _, typ := range []string{EventStarted, EventSucceeded, EventRolledBack, EventSucceeded, EventSucceeded} {
+		d.Emit(context.Background(), Event{Type: typ, JobID: typ})
+	}
+	wait(t, d)
+
+	// started não é inscrito; dos 4 restantes, só os 3 mais novos ficam no log
+	if got := len(rc.got()); got != 4 {
+		t.Errorf("requests = %d, want 4", got)
+	}
+	dels := d.Deliveries("", "")
+	if len(dels) != 3 {
+		t.Fatalf("log size = %d, want 3", len(dels))
+	}
+	if dels[2].Event != EventRolledBack {
+		t.Errorf("oldest kept = %s, want %s", dels[2].Event, EventRolledBack)
+	}
+	if got := d.Deliveries(EventSucceeded, ""); len(got) != 2 {
+		t.Errorf("filter by event = %d", len(got))
+	}
+	if got := d.Deliveries("", "other"); len(got) != 0 {
+		t.Errorf("filter by webhook = %d", len(got))
+	}
+}
+
+func TestNilDispatcher(t *testing.T) {
+	var d *Dispatcher
+	d.Emit(context.Background(), Event{Type: EventStarted})
+	d.Close(context.Background())
+	if got := d.Deliveries("", ""); len(got) != 0 {
+		t.Errorf("deliveries = %+v", got)
+	}
+	if New(config.WebhooksConfig{}) != nil {
+		t.Error("New without endpoints must return nil")
+	}
+}
@@ -2,12 +2,15 @@
 
 export const PLUGIN_ID = 'brade-dashboardtransporter-app';
 
+// rotas de negócio do backend ficam sob /api/v1 (ver GET /api/v1/openapi.json)
+export const API_PREFIX = '/api/v1';
+
 export const API_ENDPOINTS = {
-  HEALTH: '/health',
-  ENVIRONMENTS: '/environments',
-  DASHBOARDS: '/dashboards',
-  FOLDERS: '/folders',
-  IMPORT_BATCH: '/dashboards/import/batch',
+  HEALTH: '/healthz',
+  ENVIRONMENTS: `${API_PREFIX}/environments`,
+  DASHBOARDS: `${API_PREFIX}/dashboards`,
+  FOLDERS: `${API_PREFIX}/folders`,
+  IMPORT_BATCH: `${API_PREFIX}/dashboards/import/batch`,
 } as const;
 
 /**